@@ -9,7 +9,7 @@ import (
 )
 
 func main() {
-	if err := mdtoc.Command.Run(context.Background(), os.Args); err != nil {
+	if err := mdtoc.Run(context.Background(), os.Args); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -3,7 +3,19 @@ module github.com/lwmacct/251202-mc-mdtoc
 go 1.25.4
 
 require (
+	github.com/bmatcuk/doublestar/v4 v4.9.1
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/lwmacct/251207-go-pkg-version v0.0.2
+	github.com/mattn/go-runewidth v0.0.16
+	github.com/pelletier/go-toml/v2 v2.2.4
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/urfave/cli/v3 v3.6.1
 	github.com/yuin/goldmark v1.7.13
+	golang.org/x/text v0.40.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/rivo/uniseg v0.2.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
 )
@@ -2,68 +2,82 @@ package mdtoc
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/lwmacct/251202-mc-mdtoc/internal/mdtoc"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/urfave/cli/v3"
 )
 
 func action(ctx context.Context, cmd *cli.Command) error {
 	// 解析命令行参数
-	minLevel := cmd.Int("min-level")
-	maxLevel := cmd.Int("max-level")
 	inPlace := cmd.Bool("in-place")
 	deleteMode := cmd.Bool("delete")
-	ordered := cmd.Bool("ordered")
-	lineNumber := cmd.Bool("line-number")
-	showPath := cmd.Bool("path")
-	globalMode := cmd.Bool("global")
-	showAnchor := cmd.Bool("anchor")
+	checkMode := cmd.Bool("check")
+	diffMode := cmd.Bool("diff")
+	jobs := cmd.Int("jobs")
+	format := cmd.String("format")
+
+	// 选项解析器：合并命令行参数与 .mdtoc.yaml 配置
+	r := newResolver(cmd)
+
+	// 验证命令行指定的层级参数
+	var flagOpts mdtoc.Options
+	r.defaults.Merge(r.flags).Apply(&flagOpts)
+	if err := validateOptions(flagOpts); err != nil {
+		return err
+	}
 
-	// 验证层级参数
-	if minLevel < 1 || minLevel > 6 {
-		return fmt.Errorf("min-level 必须在 1-6 之间")
+	// 结构化格式只用于预览输出
+	if err := validateFormat(format); err != nil {
+		return err
+	}
+	if (isOutlineFormat(format) || format == formatTree) && (inPlace || deleteMode || checkMode || diffMode) {
+		return fmt.Errorf("--format %s 只能用于预览输出，不能与 -i/-d/-c/--diff 同时使用", format)
 	}
-	if maxLevel < 1 || maxLevel > 6 {
-		return fmt.Errorf("max-level 必须在 1-6 之间")
+
+	// 过滤模式：stdin 作为文档内容，结果写入 stdout
+	filter, err := isFilter(ctx, cmd)
+	if err != nil {
+		return err
 	}
-	if minLevel > maxLevel {
-		return fmt.Errorf("min-level 不能大于 max-level")
+	if filter {
+		return processFilter(cmd, r)
 	}
 
-	// 收集要处理的文件
-	files := collectFiles(cmd.Args().Slice())
+	// 收集要处理的文件 (目录参数会被递归遍历)
+	files, err := r.collect(collectFiles(cmd.Args().Slice()))
+	if err != nil {
+		return err
+	}
 	if len(files) == 0 {
 		// 无文件时显示帮助
 		return cli.ShowSubcommandHelp(cmd)
 	}
 
-	// 创建基础选项
-	// 默认启用章节模式 (SectionTOC=true)，只有指定 --global 才使用全局模式
-	baseOpts := mdtoc.Options{
-		MinLevel:   int(minLevel),
-		MaxLevel:   int(maxLevel),
-		Ordered:    ordered,
-		LineNumber: lineNumber,
-		ShowPath:   showPath,
-		SectionTOC: !globalMode,
-		ShowAnchor: showAnchor, // 预览模式使用用户指定值
-	}
-
 	// 根据模式执行不同操作
+	// 默认启用章节模式 (SectionTOC=true)，只有指定 --global 才使用全局模式
 	switch {
+	case checkMode:
+		// check 模式与 inPlace 使用相同的写入选项，保证比较结果一致
+		return processCheck(r, files, jobs)
+	case diffMode:
+		// diff 模式预览写入结果，与 inPlace 使用相同的写入选项
+		return processDiff(r, files, deleteMode, jobs)
 	case deleteMode:
-		return processDelete(mdtoc.New(baseOpts), files)
+		return processDelete(r, files, jobs)
 	case inPlace:
-		// inPlace 模式强制启用 ShowAnchor（写入文件必须有链接）
-		writeOpts := baseOpts
-		writeOpts.ShowAnchor = true
-		return processInPlace(mdtoc.New(writeOpts), files)
+		return processInPlace(r, files, jobs)
+	case format == formatTree:
+		return processTree(r, files, jobs)
+	case isOutlineFormat(format):
+		return processOutline(r, files, jobs, format)
 	default:
-		return processStdout(baseOpts, files)
+		return processStdout(r, files, jobs)
 	}
 }
 
@@ -104,27 +118,35 @@ func isTerminal(f *os.File) bool {
 }
 
 // processDelete 删除模式 - 删除文件中的 TOC
-func processDelete(toc *mdtoc.TOC, files []string) error {
+func processDelete(r *resolver, files []string, jobs int) error {
 	var errors []string
 
-	for _, file := range files {
+	forEachOrdered(files, jobs, func(file string) fileResult {
 		if err := checkFileExists(file); err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", file, err))
-			continue
+			return fileResult{err: err}
+		}
+
+		toc, err := r.newTOC(file, false)
+		if err != nil {
+			return fileResult{err: err}
 		}
 
 		deleted, err := toc.DeleteTOC(file)
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", file, err))
-			continue
+			return fileResult{err: err}
 		}
 
 		if deleted {
-			fmt.Printf("%s: TOC 已删除\n", file)
-		} else {
-			fmt.Printf("%s: 无 TOC 标记\n", file)
+			return fileResult{output: fmt.Sprintf("%s: TOC 已删除\n", file)}
 		}
-	}
+		return fileResult{output: fmt.Sprintf("%s: 无 TOC 标记\n", file)}
+	}, func(_ int, file string, res fileResult) {
+		if res.err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", file, res.err))
+			return
+		}
+		fmt.Print(res.output)
+	})
 
 	if len(errors) > 0 {
 		return fmt.Errorf("部分文件处理失败:\n%s", strings.Join(errors, "\n"))
@@ -134,71 +156,201 @@ func processDelete(toc *mdtoc.TOC, files []string) error {
 
 // processInPlace 原地更新模式
 // 如果文件没有 TOC 标记，会自动在第一个标题后插入
-func processInPlace(toc *mdtoc.TOC, files []string) error {
+func processInPlace(r *resolver, files []string, jobs int) error {
 	var errors []string
 
-	for _, file := range files {
+	forEachOrdered(files, jobs, func(file string) fileResult {
 		if err := checkFileExists(file); err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", file, err))
-			continue
+			return fileResult{err: err}
+		}
+
+		// inPlace 模式强制启用 ShowAnchor（写入文件必须有链接）
+		toc, err := r.newTOC(file, true)
+		if err != nil {
+			return fileResult{err: err}
 		}
 
 		hasMarker, _ := toc.HasMarker(file)
 
-		if err := toc.UpdateFile(file); err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", file, err))
-			continue
+		changed, err := toc.UpdateFile(file)
+		if err != nil {
+			return fileResult{err: err}
 		}
 
-		if hasMarker {
-			fmt.Printf("%s: 已更新\n", file)
-		} else {
-			fmt.Printf("%s: 已插入 (在第一个标题后)\n", file)
+		switch {
+		case !changed:
+			return fileResult{output: fmt.Sprintf("%s: 未变化\n", file)}
+		case hasMarker:
+			return fileResult{output: fmt.Sprintf("%s: 已更新\n", file)}
+		default:
+			return fileResult{output: fmt.Sprintf("%s: 已插入 (在第一个标题后)\n", file)}
 		}
+	}, func(_ int, file string, res fileResult) {
+		if res.err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", file, res.err))
+			return
+		}
+		fmt.Print(res.output)
+	})
+
+	if len(errors) > 0 {
+		return fmt.Errorf("部分文件处理失败:\n%s", strings.Join(errors, "\n"))
 	}
+	return nil
+}
+
+// processCheck 检查模式 - 在内存中执行更新流程，列出 TOC 已过期的文件
+// 不写入任何文件，存在过期文件时返回错误 (非零退出码)
+func processCheck(r *resolver, files []string, jobs int) error {
+	var errors []string
+	var stale []string
+
+	forEachOrdered(files, jobs, func(file string) fileResult {
+		if err := checkFileExists(file); err != nil {
+			return fileResult{err: err}
+		}
+
+		toc, err := r.newTOC(file, true)
+		if err != nil {
+			return fileResult{err: err}
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fileResult{err: err}
+		}
+
+		upToDate, err := toc.IsUpToDate(content)
+		if err != nil {
+			return fileResult{err: err}
+		}
+		return fileResult{stale: !upToDate}
+	}, func(_ int, file string, res fileResult) {
+		if res.err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", file, res.err))
+			return
+		}
+		if res.stale {
+			stale = append(stale, file)
+			fmt.Printf("%s: TOC 已过期\n", file)
+		}
+	})
 
 	if len(errors) > 0 {
 		return fmt.Errorf("部分文件处理失败:\n%s", strings.Join(errors, "\n"))
 	}
+	if len(stale) > 0 {
+		return fmt.Errorf("%d 个文件的 TOC 已过期，请运行 mc-mdtoc -i 更新", len(stale))
+	}
 	return nil
 }
 
-// processStdout 输出到 stdout 模式
-func processStdout(baseOpts mdtoc.Options, files []string) error {
-	for i, file := range files {
+// processDiff 差异预览模式 - 计算更新 (或删除) 后的内容，输出 unified diff
+// 不写入任何文件，内容无变化的文件不输出；存在差异时返回错误 (非零退出码)
+func processDiff(r *resolver, files []string, deleteMode bool, jobs int) error {
+	var errors []string
+	changed := 0
+
+	forEachOrdered(files, jobs, func(file string) fileResult {
 		if err := checkFileExists(file); err != nil {
-			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
-			continue
+			return fileResult{err: err}
 		}
 
-		// 为每个文件创建带有文件路径的 TOC 实例
-		opts := baseOpts
-		opts.FilePath = file
-		toc := mdtoc.New(opts)
+		toc, err := r.newTOC(file, true)
+		if err != nil {
+			return fileResult{err: err}
+		}
 
-		var tocStr string
-		var err error
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fileResult{err: err}
+		}
 
-		if opts.SectionTOC {
-			// 章节模式：预览每个 H1 的子目录
-			content, readErr := os.ReadFile(file)
-			if readErr != nil {
-				fmt.Fprintf(os.Stderr, "%s: %v\n", file, readErr)
-				continue
-			}
-			tocStr, err = toc.GenerateSectionTOCsPreview(content)
+		var newContent []byte
+		if deleteMode {
+			newContent, _ = toc.DeleteContent(content)
 		} else {
-			tocStr, err = toc.GenerateFromFile(file)
+			newContent, err = toc.UpdateContent(content)
+			if err != nil {
+				return fileResult{err: err}
+			}
+		}
+
+		diff, err := unifiedDiff(file, content, newContent)
+		if err != nil {
+			return fileResult{err: err}
+		}
+		return fileResult{output: diff}
+	}, func(_ int, file string, res fileResult) {
+		if res.err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", file, res.err))
+			return
+		}
+		if res.output != "" {
+			changed++
+		}
+		fmt.Print(res.output)
+	})
+
+	if len(errors) > 0 {
+		return fmt.Errorf("部分文件处理失败:\n%s", strings.Join(errors, "\n"))
+	}
+	if changed > 0 {
+		return fmt.Errorf("%d 个文件存在差异", changed)
+	}
+	return nil
+}
+
+// unifiedDiff 生成 unified diff 格式的差异，内容相同时返回空字符串
+func unifiedDiff(file string, before, after []byte) (string, error) {
+	if bytes.Equal(before, after) {
+		return "", nil
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: "a/" + file,
+		ToFile:   "b/" + file,
+		Context:  3,
+	})
+}
+
+// processStdout 输出到 stdout 模式
+func processStdout(r *resolver, files []string, jobs int) error {
+	var errors []string
+
+	forEachOrdered(files, jobs, func(file string) fileResult {
+		if err := checkFileExists(file); err != nil {
+			return fileResult{err: err}
+		}
+
+		// 为每个文件创建带有文件路径的 TOC 实例 (预览模式使用用户指定的 ShowAnchor)
+		opts, err := r.options(file)
+		if err != nil {
+			return fileResult{err: err}
 		}
+		toc := mdtoc.New(opts)
 
+		// 根据章节模式或全局模式生成预览 (文档 frontmatter 可覆盖模式)
+		content, err := os.ReadFile(file)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
-			continue
+			return fileResult{err: err}
+		}
+
+		tocStr, err := toc.Preview(content)
+		if err != nil {
+			return fileResult{err: err}
+		}
+		return fileResult{output: tocStr}
+	}, func(i int, file string, res fileResult) {
+		if res.err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", file, res.err))
+			return
 		}
 
 		// 跳过空的 TOC
-		if strings.TrimSpace(tocStr) == "" {
-			continue
+		if strings.TrimSpace(res.output) == "" {
+			return
 		}
 
 		// 多文件时添加文件名标题
@@ -206,14 +358,17 @@ func processStdout(baseOpts mdtoc.Options, files []string) error {
 			fmt.Printf("## %s\n\n", file)
 		}
 
-		fmt.Println(tocStr)
+		fmt.Println(res.output)
 
 		// 多文件时添加分隔
 		if len(files) > 1 && i < len(files)-1 {
 			fmt.Println()
 		}
-	}
+	})
 
+	if len(errors) > 0 {
+		return fmt.Errorf("部分文件处理失败:\n%s", strings.Join(errors, "\n"))
+	}
 	return nil
 }
 
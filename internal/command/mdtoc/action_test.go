@@ -0,0 +1,121 @@
+package mdtoc
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "更新 testdata 中的 golden 文件")
+
+// checkGolden 对比 testdata/diff 中的 golden 文件，-update 时重新生成
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "diff", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if expected := readFile(t, path); got != expected {
+		t.Errorf("output differs from %s:\n%s\nwant\n%s", path, got, expected)
+	}
+}
+
+// TestUnifiedDiff 对过期和最新的文档计算 -i 写入前后的差异
+// 修改输出格式后使用 go test -update 重新生成 golden 文件
+func TestUnifiedDiff(t *testing.T) {
+	r := testResolver(t)
+
+	tests := []struct {
+		file   string
+		golden string // 为空时期望没有差异
+	}{
+		{"testdata/diff/stale.md", "stale.diff"},
+		{"testdata/diff/uptodate.md", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.file), func(t *testing.T) {
+			toc, err := r.newTOC(tt.file, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content := []byte(readFile(t, tt.file))
+			newContent, err := toc.UpdateContent(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := unifiedDiff(tt.file, content, newContent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.golden == "" {
+				if got != "" {
+					t.Errorf("unifiedDiff() = %q, want empty", got)
+				}
+				return
+			}
+			if !strings.HasPrefix(got, "--- a/"+tt.file+"\n+++ b/"+tt.file+"\n") {
+				t.Errorf("unifiedDiff() header = %q, want a/ and b/ paths", strings.SplitN(got, "@@", 2)[0])
+			}
+			checkGolden(t, tt.golden, got)
+		})
+	}
+}
+
+func TestProcessDiff(t *testing.T) {
+	stale := "testdata/diff/stale.md"
+	uptodate := "testdata/diff/uptodate.md"
+	before := readFile(t, stale)
+
+	// 存在差异时输出 diff 并返回错误 (非零退出码)
+	out, err := runCommand(t, "", "--diff", stale)
+	if err == nil || !strings.Contains(err.Error(), "1 个文件存在差异") {
+		t.Errorf("--diff error = %v, want diff error", err)
+	}
+	checkGolden(t, "stale.diff", out)
+
+	// 已是最新时不输出
+	out, err = runCommand(t, "", "--diff", uptodate)
+	if err != nil {
+		t.Errorf("--diff up-to-date error = %v", err)
+	}
+	if out != "" {
+		t.Errorf("--diff up-to-date stdout = %q, want empty", out)
+	}
+
+	// -d 预览删除 TOC 的改动
+	out, err = runCommand(t, "", "--diff", "-d", uptodate)
+	if err == nil {
+		t.Error("--diff -d should return error when TOC would be deleted")
+	}
+	checkGolden(t, "uptodate.delete.diff", out)
+
+	// 多个文件按输入顺序输出，只统计有差异的文件
+	out, err = runCommand(t, "", "--diff", uptodate, stale)
+	if err == nil || !strings.Contains(err.Error(), "1 个文件存在差异") {
+		t.Errorf("--diff multiple files error = %v", err)
+	}
+	if !strings.HasPrefix(out, "--- a/"+stale) {
+		t.Errorf("--diff multiple files stdout should start with %s diff, got:\n%s", stale, out)
+	}
+
+	// 过滤模式同样在有差异时返回错误
+	out, err = runCommand(t, before, "--diff", "-")
+	if err == nil {
+		t.Error("--diff - should return error when stdin is stale")
+	}
+	if !strings.HasPrefix(out, "--- a/<stdin>\n+++ b/<stdin>\n") {
+		t.Errorf("--diff - stdout should use <stdin> as path, got:\n%s", out)
+	}
+
+	// 不写入文件
+	if readFile(t, stale) != before {
+		t.Errorf("--diff modified %s", stale)
+	}
+}
@@ -1,66 +1,163 @@
 package mdtoc
 
 import (
+	"context"
+
+	"github.com/lwmacct/251202-mc-mdtoc/internal/mdtoc"
 	"github.com/lwmacct/251207-go-pkg-version/pkg/version"
 	"github.com/urfave/cli/v3"
 )
 
-// Command 返回 mc-mdtoc 主命令
-var Command = &cli.Command{
-	Name:     "mc-mdtoc",
-	Usage:    "生成和查看 Markdown 文档的大纲 (TOC)",
-	Commands: []*cli.Command{version.Command},
-	UsageText: `mc-mdtoc [options] <file>...
-fd -e md | mc-mdtoc`,
-	Flags: []cli.Flag{
-		&cli.IntFlag{
-			Name:    "min-level",
-			Aliases: []string{"m"},
-			Value:   1,
-			Usage:   "最小标题层级 (1-6)",
-		},
-		&cli.IntFlag{
-			Name:    "max-level",
-			Aliases: []string{"M"},
-			Value:   3,
-			Usage:   "最大标题层级 (1-6)",
-		},
-		&cli.BoolFlag{
-			Name:    "in-place",
-			Aliases: []string{"i"},
-			Usage:   "原地更新文件 (在 <!--TOC--> 标记处插入)",
-		},
-		&cli.BoolFlag{
-			Name:    "delete",
-			Aliases: []string{"d"},
-			Usage:   "删除文件中的 TOC 标记和内容",
-		},
-		&cli.BoolFlag{
-			Name:    "ordered",
-			Aliases: []string{"o"},
-			Usage:   "使用有序列表 (1. 2. 3.)",
-		},
-		&cli.BoolFlag{
-			Name:    "line-number",
-			Aliases: []string{"L"},
-			Value:   true,
-			Usage:   "显示行号范围 (:start:end)",
-		},
-		&cli.BoolFlag{
-			Name:    "path",
-			Aliases: []string{"p"},
-			Usage:   "显示文件路径 (path:start:end)",
-		},
-		&cli.BoolFlag{
-			Name:    "global",
-			Aliases: []string{"g"},
-			Usage:   "全局模式: 生成完整文档的单一目录 (默认为章节模式)",
-		},
-		&cli.BoolFlag{
-			Name:    "anchor",
-			Aliases: []string{"a"},
-			Usage:   "预览时显示锚点链接 [标题](#anchor)",
+// Command mc-mdtoc 主命令
+var Command = newCommand()
+
+// Run 使用命令行参数 (含程序名) 执行主命令
+// 原始参数保存在 context 中，用于检查 flag 解析器丢弃的参数
+func Run(ctx context.Context, args []string) error {
+	return Command.Run(withRawArgs(ctx, args), args)
+}
+
+// newCommand 创建主命令
+// flag 在解析后会保留状态，测试中每次运行都需要新建命令
+func newCommand() *cli.Command {
+	return &cli.Command{
+		Name:     "mc-mdtoc",
+		Usage:    "生成和查看 Markdown 文档的大纲 (TOC)",
+		Commands: []*cli.Command{version.Command, configCommand, watchCommand},
+		UsageText: `mc-mdtoc [options] <file|dir>...
+	fd -e md | mc-mdtoc
+	mc-mdtoc -i - < README.md`,
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "min-level",
+				Aliases: []string{"m"},
+				Value:   1,
+				Usage:   "最小标题层级 (1-6)",
+			},
+			&cli.IntFlag{
+				Name:    "max-level",
+				Aliases: []string{"M"},
+				Value:   3,
+				Usage:   "最大标题层级 (1-6)",
+			},
+			&cli.BoolFlag{
+				Name:    "in-place",
+				Aliases: []string{"i"},
+				Usage:   "原地更新文件 (在 <!--TOC--> 标记处插入)",
+			},
+			&cli.BoolFlag{
+				Name:    "delete",
+				Aliases: []string{"d"},
+				Usage:   "删除文件中的 TOC 标记和内容",
+			},
+			&cli.BoolFlag{
+				Name:    "check",
+				Aliases: []string{"c"},
+				Usage:   "检查 TOC 是否最新 (不写入文件，存在过期文件时返回非零退出码)",
+			},
+			&cli.BoolFlag{
+				Name:  "diff",
+				Usage: "预览原地更新 (或 -d 删除) 的改动，以 unified diff 格式输出，不写入文件 (有改动时返回非零退出码)",
+			},
+			&cli.BoolFlag{
+				Name:    "ordered",
+				Aliases: []string{"o"},
+				Usage:   "使用有序列表 (1. 2. 3.)",
+			},
+			&cli.BoolFlag{
+				Name:    "line-number",
+				Aliases: []string{"L"},
+				Value:   true,
+				Usage:   "显示行号范围 (格式由 --line-style 决定)",
+			},
+			&cli.StringFlag{
+				Name:  "line-style",
+				Value: mdtoc.LineStylePlus,
+				Usage: "行号范围格式: plus (:10+11), colon (10:20), github (L10-L20), dash (10-20), start (:10，配合 -p 输出 path:10)",
+			},
+			&cli.BoolFlag{
+				Name:  "line-exclusive",
+				Usage: "父标题的行号范围结束于第一个子标题之前 (默认包含所有子标题内容)",
+			},
+			&cli.BoolFlag{
+				Name:    "path",
+				Aliases: []string{"p"},
+				Usage:   "显示文件路径 (path:start:end)",
+			},
+			&cli.BoolFlag{
+				Name:    "global",
+				Aliases: []string{"g"},
+				Usage:   "全局模式: 生成完整文档的单一目录 (默认为章节模式)",
+			},
+			&cli.BoolFlag{
+				Name:    "anchor",
+				Aliases: []string{"a"},
+				Usage:   "预览时显示锚点链接 [标题](#anchor)",
+			},
+			&cli.StringFlag{
+				Name:  "list-style",
+				Usage: "列表格式: default, prettier, markdownlint (prettier 的别名)，可追加 bullet=-|*|+, indent=N, align, delimiter=.|), numbering=increment|one (逗号分隔)",
+			},
+			&cli.StringFlag{
+				Name:  "collapse",
+				Usage: "写入的 TOC 折叠为 <details>: details (整体折叠), groups (每个带子条目的顶层条目再折叠为一组), none",
+			},
+			&cli.StringFlag{
+				Name:  "summary",
+				Usage: "折叠 TOC 的 <summary> 文本 (默认 Contents)",
+			},
+			&cli.StringFlag{
+				Name:  "slug",
+				Usage: "锚点生成规则: github (默认), gitlab, vitepress, hugo, hugo-ascii, hugo-blackfriday, pandoc, python-markdown (MkDocs)，与文档的渲染平台一致",
+			},
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Value:   formatMarkdown,
+				Usage:   "输出格式: markdown, html, mermaid (可用于 -i 写入), tree (终端树形视图), json, yaml, toml (结构化格式输出带版本号的标题树), opml (大纲/思维导图工具)",
+			},
+			&cli.StringFlag{
+				Name:  "template",
+				Usage: "使用 Go text/template 模板文件渲染 TOC 条目 (可定义 entry/header/footer)",
+			},
+			&cli.StringFlag{
+				Name:  "permalink",
+				Usage: "行号范围链接到源码托管平台: github, gitlab, gitea, file 或 URL 模板 (占位符 {base} {ref} {path} {abs} {start} {end})",
+			},
+			&cli.StringFlag{
+				Name:  "permalink-base",
+				Usage: "永久链接的仓库网页地址 (默认从 git remote origin 推断)",
+			},
+			&cli.StringFlag{
+				Name:  "permalink-ref",
+				Usage: "永久链接的分支、标签或提交 (默认为 origin 远程的默认分支 origin/HEAD)",
+			},
+			&cli.StringFlag{
+				Name:  "html-class",
+				Usage: "HTML 格式下列表项的 CSS 类名前缀，按层级追加数字 (如 toc-h 生成 toc-h2)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "include",
+				Usage: "遍历目录时包含的文件 glob 模式 (默认 *.md，可多次指定)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "遍历目录时排除的文件或目录 glob 模式 (可多次指定)",
+			},
+			&cli.BoolFlag{
+				Name:  "stdin",
+				Usage: "过滤模式: 从 stdin 读取文档内容，处理结果输出到 stdout (等同于文件参数 -)",
+			},
+			&cli.StringFlag{
+				Name:  "stdin-path",
+				Usage: "过滤模式下文档对应的文件路径，用于查找配置文件和显示路径",
+			},
+			&cli.IntFlag{
+				Name:    "jobs",
+				Aliases: []string{"j"},
+				Usage:   "并发处理的文件数 (0 表示使用 CPU 核数)，输出顺序与输入一致",
+			},
 		},
-	},
-	Action: action,
+		Action: action,
+	}
 }
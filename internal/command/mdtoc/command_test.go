@@ -0,0 +1,193 @@
+package mdtoc
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+// runCommand 以 stdin 为输入执行主命令，返回 stdout 的内容
+// 每次运行使用新建的命令，避免 flag 状态在测试之间残留
+func runCommand(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	in, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	if _, err := in.WriteString(stdin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	oldStdin, oldStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = in, out
+	defer func() { os.Stdin, os.Stdout = oldStdin, oldStdout }()
+
+	args = append([]string{"mc-mdtoc"}, args...)
+	runErr := newCommand().Run(withRawArgs(context.Background(), args), args)
+
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := io.ReadAll(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(stdout), runErr
+}
+
+// testResolver 按命令行参数创建选项解析器
+func testResolver(t *testing.T, args ...string) *resolver {
+	t.Helper()
+	var r *resolver
+	cmd := newCommand()
+	cmd.Action = func(_ context.Context, cmd *cli.Command) error {
+		r = newResolver(cmd)
+		return nil
+	}
+	if err := cmd.Run(context.Background(), append([]string{"mc-mdtoc"}, args...)); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// writeFile 写入测试文件
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readFile 读取测试文件
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestFilter_FileArgs(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deep.md")
+	writeFile(t, file, "# Deep\n\n## A\n")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"stdin first", []string{"-i", "-", file}},
+		{"stdin last", []string{"-i", file, "-"}},
+		{"flag after stdin", []string{"-i", "-", "-M", "2"}},
+		{"stdin flag", []string{"--stdin", file}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runCommand(t, "# Title\n\n## B\n", tt.args...)
+			if err == nil || !strings.Contains(err.Error(), "不能同时指定文件参数") {
+				t.Errorf("run(%q) error = %v, want file argument error", tt.args, err)
+			}
+			if out != "" {
+				t.Errorf("run(%q) stdout = %q, want empty", tt.args, out)
+			}
+		})
+	}
+
+	// 文件参数未被处理
+	if got := readFile(t, file); got != "# Deep\n\n## A\n" {
+		t.Errorf("file modified:\n%s", got)
+	}
+}
+
+func TestFilter_InPlace(t *testing.T) {
+	out, err := runCommand(t, "# Title\n\n## A\n", "-M", "2", "-i", "-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Title\n\n<!--TOC-->\n\n- [A](#a) `:9+1`\n\n<!--TOC-->\n\n## A\n"
+	if out != want {
+		t.Errorf("stdout =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestFilter_Formats(t *testing.T) {
+	content := "# Title\n\n## A\n\n### B\n"
+
+	out, err := runCommand(t, content, "--stdin", "--format", "tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<stdin>\n└── Title      :1+5\n    └── A      :3+3\n        └── B  :5+1\n"; out != want {
+		t.Errorf("tree stdout =\n%s\nwant\n%s", out, want)
+	}
+
+	out, err = runCommand(t, content, "--stdin", "--format", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `"path": "<stdin>"`) {
+		t.Errorf("json stdout should contain file path, got:\n%s", out)
+	}
+}
+
+func TestTree_MissingFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.md")
+	writeFile(t, file, "# A\n")
+	missing := filepath.Join(dir, "missing.md")
+
+	out, err := runCommand(t, "", "--format", "tree", file, missing)
+	if err == nil || !strings.Contains(err.Error(), "部分文件处理失败") || !strings.Contains(err.Error(), missing) {
+		t.Errorf("tree error = %v, want failure for %s", err, missing)
+	}
+	if !strings.Contains(out, "└── A") {
+		t.Errorf("tree stdout should still contain existing file, got:\n%s", out)
+	}
+}
+
+func TestTemplate_InPlaceFilePath(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "toc.tmpl")
+	writeFile(t, tmpl, `{{define "header"}}**Contents of {{.FilePath}}**{{"\n\n"}}{{end}}{{define "entry"}}- [{{.Text}}](#{{.AnchorLink}}) {{.FilePath}}{{end}}`)
+	file := filepath.Join(dir, "README.md")
+	writeFile(t, file, "# Title\n\n## A\n")
+
+	if _, err := runCommand(t, "", "-i", "-M", "2", "--template", tmpl, file); err != nil {
+		t.Fatal(err)
+	}
+	got := readFile(t, file)
+	if !strings.Contains(got, "**Contents of "+file+"**") || !strings.Contains(got, "- [A](#a) "+file) {
+		t.Errorf("template should receive file path in -i mode, got:\n%s", got)
+	}
+}
+
+func TestInPlace_LineStylePath(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.md")
+	writeFile(t, file, "# Title\n\n## A\n")
+
+	if _, err := runCommand(t, "", "-i", "-M", "2", "-p", "--line-style", "start", file); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, file); !strings.Contains(got, "- [A](#a) `"+file+":9`") {
+		t.Errorf("-p should keep path prefix in -i mode, got:\n%s", got)
+	}
+}
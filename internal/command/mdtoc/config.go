@@ -0,0 +1,51 @@
+package mdtoc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// configCommand 打印文件生效的配置
+var configCommand = &cli.Command{
+	Name:      "config",
+	Usage:     "打印文件生效的选项 (合并 .mdtoc.yaml 与命令行参数)",
+	UsageText: "mc-mdtoc [options] config <file>...",
+	Action:    configAction,
+}
+
+func configAction(ctx context.Context, cmd *cli.Command) error {
+	files := cmd.Args().Slice()
+	if len(files) == 0 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+
+	r := newResolver(cmd)
+
+	for i, file := range files {
+		s, cfg, err := r.settings(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		out, err := yaml.Marshal(s)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			fmt.Println("---")
+		}
+		fmt.Printf("# file: %s\n", file)
+		if cfg != nil {
+			fmt.Printf("# config: %s\n", cfg.Path())
+		} else {
+			fmt.Println("# config: (无配置文件)")
+		}
+		fmt.Print(string(out))
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package mdtoc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/lwmacct/251202-mc-mdtoc/internal/mdtoc"
+	"github.com/urfave/cli/v3"
+)
+
+// rawArgsKey 是 context 中原始命令行参数的 key
+type rawArgsKey struct{}
+
+// withRawArgs 在 context 中保存原始命令行参数
+func withRawArgs(ctx context.Context, args []string) context.Context {
+	return context.WithValue(ctx, rawArgsKey{}, args)
+}
+
+// rawArgs 返回原始命令行参数 (不含程序名)，未保存时返回 nil
+func rawArgs(ctx context.Context) []string {
+	args, _ := ctx.Value(rawArgsKey{}).([]string)
+	if len(args) == 0 {
+		return nil
+	}
+	return args[1:]
+}
+
+// isFilter 检查是否为过滤模式 (--stdin 或唯一的文件参数为 -)
+func isFilter(ctx context.Context, cmd *cli.Command) (bool, error) {
+	errArgs := fmt.Errorf("过滤模式从 stdin 读取文档，不能同时指定文件参数 (选项需放在 - 之前)")
+
+	args := cmd.Args().Slice()
+	stdinArg := hasStdinArg(args)
+	if !cmd.Bool("stdin") && !stdinArg {
+		return false, nil
+	}
+	if cmd.Bool("stdin") && len(args) > 0 || len(args) > 1 {
+		return false, errArgs
+	}
+
+	// flag 解析器遇到 - 后停止解析并丢弃其后的所有参数 ("-i - a.md" 解析为 [-])，
+	// 需要检查原始参数，- 之后还有参数时报错而不是静默忽略
+	if stdinArg {
+		raw := rawArgs(ctx)
+		if i := slices.Index(raw, "-"); i >= 0 && i < len(raw)-1 {
+			return false, errArgs
+		}
+	}
+	return true, nil
+}
+
+// hasStdinArg 检查参数中是否包含 -
+func hasStdinArg(args []string) bool {
+	for _, arg := range args {
+		if arg == "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// processFilter 过滤模式 - 从 stdin 读取文档内容，将完整的处理结果写入 stdout
+// 与文件模式使用相同的更新/删除流程，不读写任何文件，可用作编辑器过滤器 (:%!mc-mdtoc -i -)
+// --stdin-path 指定内容对应的文件路径，用于查找配置文件和显示路径
+func processFilter(cmd *cli.Command, r *resolver) error {
+	file := cmd.String("stdin-path")
+	label := file
+	if label == "" {
+		label = "<stdin>"
+	}
+
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	// 预览模式 - 与 processStdout 一致，使用用户指定的 ShowAnchor
+	if !cmd.Bool("check") && !cmd.Bool("diff") && !cmd.Bool("delete") && !cmd.Bool("in-place") {
+		opts, err := r.options(file)
+		if err != nil {
+			return err
+		}
+
+		format := cmd.String("format")
+		if format == formatTree {
+			out, err := renderTree(opts, label, content, isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "")
+			if err != nil {
+				return err
+			}
+			fmt.Println(out)
+			return nil
+		}
+		if isOutlineFormat(format) {
+			fo, err := mdtoc.New(opts).Outline(label, content)
+			if err != nil {
+				return err
+			}
+			outline := mdtoc.NewOutline()
+			outline.Files = append(outline.Files, fo)
+			out, err := marshalOutline(outline, format)
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(out)
+			return err
+		}
+
+		tocStr, err := mdtoc.New(opts).Preview(content)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(tocStr) != "" {
+			fmt.Println(tocStr)
+		}
+		return nil
+	}
+
+	toc, err := r.newTOC(file, true)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Bool("check") {
+		upToDate, err := toc.IsUpToDate(content)
+		if err != nil {
+			return err
+		}
+		if !upToDate {
+			return fmt.Errorf("%s: TOC 已过期", label)
+		}
+		return nil
+	}
+
+	var newContent []byte
+	if cmd.Bool("delete") {
+		newContent, _ = toc.DeleteContent(content)
+	} else if newContent, err = toc.UpdateContent(content); err != nil {
+		return err
+	}
+
+	if cmd.Bool("diff") {
+		diff, err := unifiedDiff(label, content, newContent)
+		if err != nil {
+			return err
+		}
+		fmt.Print(diff)
+		if diff != "" {
+			return fmt.Errorf("%s: 存在差异", label)
+		}
+		return nil
+	}
+
+	_, err = os.Stdout.Write(newContent)
+	return err
+}
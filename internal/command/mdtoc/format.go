@@ -0,0 +1,183 @@
+package mdtoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lwmacct/251202-mc-mdtoc/internal/mdtoc"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// 预览输出格式
+const (
+	formatMarkdown = "markdown"
+	formatHTML     = "html"
+	formatMermaid  = "mermaid"
+	formatTree     = "tree"
+	formatJSON     = "json"
+	formatYAML     = "yaml"
+	formatTOML     = "toml"
+	formatOPML     = "opml"
+)
+
+// formats 支持的输出格式
+var formats = []string{formatMarkdown, formatHTML, formatMermaid, formatTree, formatJSON, formatYAML, formatTOML, formatOPML}
+
+// validateFormat 验证输出格式
+func validateFormat(format string) error {
+	for _, f := range formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("不支持的输出格式 %q (可选: %s)", format, strings.Join(formats, ", "))
+}
+
+// isOutlineFormat 检查是否为结构化大纲格式 (输出标题树数据，而不是 TOC 文本)
+func isOutlineFormat(format string) bool {
+	return format == formatJSON || format == formatYAML || format == formatTOML || format == formatOPML
+}
+
+// isHTMLFile 检查文件是否为 HTML 文档
+func isHTMLFile(file string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	return ext == ".html" || ext == ".htm"
+}
+
+// processOutline 结构化大纲输出模式 - 所有文件的大纲合并为一个文档输出到 stdout
+// 文件顺序与输入一致，失败的文件不出现在结果中并在最后汇总报告
+func processOutline(r *resolver, files []string, jobs int, format string) error {
+	var errors []string
+	outline := mdtoc.NewOutline()
+
+	forEachOrdered(files, jobs, func(file string) fileResult {
+		if err := checkFileExists(file); err != nil {
+			return fileResult{err: err}
+		}
+
+		opts, err := r.options(file)
+		if err != nil {
+			return fileResult{err: err}
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fileResult{err: err}
+		}
+
+		fo, err := mdtoc.New(opts).Outline(file, content)
+		if err != nil {
+			return fileResult{err: err}
+		}
+		return fileResult{outline: fo}
+	}, func(_ int, file string, res fileResult) {
+		if res.err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", file, res.err))
+			return
+		}
+		outline.Files = append(outline.Files, res.outline)
+	})
+
+	out, err := marshalOutline(outline, format)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stdout.Write(out); err != nil {
+		return err
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("部分文件处理失败:\n%s", strings.Join(errors, "\n"))
+	}
+	return nil
+}
+
+// marshalOutline 按格式序列化大纲
+func marshalOutline(outline *mdtoc.Outline, format string) ([]byte, error) {
+	switch format {
+	case formatJSON:
+		// 标题文本可能包含 <、> 和 &，输出保持原样，不转义为 \u003c 等形式
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(outline); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case formatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(outline); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case formatTOML:
+		return toml.Marshal(outline)
+	case formatOPML:
+		return outline.OPML()
+	default:
+		return nil, fmt.Errorf("不支持的输出格式 %q", format)
+	}
+}
+
+// processTree 终端树形视图模式
+// stdout 为终端且未设置 NO_COLOR 时按标题层级着色；失败的文件在最后汇总报告
+func processTree(r *resolver, files []string, jobs int) error {
+	var errors []string
+	color := isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+
+	forEachOrdered(files, jobs, func(file string) fileResult {
+		if err := checkFileExists(file); err != nil {
+			return fileResult{err: err}
+		}
+
+		opts, err := r.options(file)
+		if err != nil {
+			return fileResult{err: err}
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fileResult{err: err}
+		}
+
+		out, err := renderTree(opts, file, content, color)
+		if err != nil {
+			return fileResult{err: err}
+		}
+		return fileResult{output: out}
+	}, func(i int, file string, res fileResult) {
+		if res.err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", file, res.err))
+			return
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(res.output)
+	})
+
+	if len(errors) > 0 {
+		return fmt.Errorf("部分文件处理失败:\n%s", strings.Join(errors, "\n"))
+	}
+	return nil
+}
+
+// renderTree 渲染单个文档的树形视图
+func renderTree(opts mdtoc.Options, file string, content []byte, color bool) (string, error) {
+	fo, err := mdtoc.New(opts).Outline(file, content)
+	if err != nil {
+		return "", err
+	}
+	return mdtoc.RenderTree(fo, mdtoc.TreeOptions{Color: color, LineNumber: opts.LineNumber, LineStyle: opts.LineStyle}), nil
+}
@@ -0,0 +1,58 @@
+package mdtoc
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/lwmacct/251202-mc-mdtoc/internal/mdtoc"
+)
+
+// fileResult 单个文件的处理结果
+type fileResult struct {
+	output string // 输出到 stdout 的内容
+	err    error  // 处理失败的原因
+	stale  bool   // check 模式: TOC 是否已过期
+
+	outline *mdtoc.FileOutline // 结构化输出模式: 文件大纲
+}
+
+// jobCount 计算并发数，n <= 0 时使用 CPU 核数，且不超过文件数
+func jobCount(n, files int) int {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	return max(1, min(n, files))
+}
+
+// forEachOrdered 使用 jobs 个 worker 并发处理文件
+// process 在 worker 中执行；emit 在调用方 goroutine 中按输入顺序依次执行，
+// 前面的文件完成后立即输出，保证 stdout 与错误汇总的顺序与输入一致
+func forEachOrdered(files []string, jobs int, process func(file string) fileResult, emit func(i int, file string, res fileResult)) {
+	jobs = jobCount(jobs, len(files))
+
+	results := make([]chan fileResult, len(files))
+	for i := range results {
+		results[i] = make(chan fileResult, 1)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Go(func() {
+			for i := range indexes {
+				results[i] <- process(files[i])
+			}
+		})
+	}
+	go func() {
+		for i := range files {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+
+	for i, file := range files {
+		emit(i, file, <-results[i])
+	}
+	wg.Wait()
+}
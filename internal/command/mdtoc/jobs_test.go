@@ -0,0 +1,94 @@
+package mdtoc
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJobCount(t *testing.T) {
+	tests := []struct {
+		n, files int
+		expected int
+	}{
+		{4, 10, 4},
+		{4, 2, 2},
+		{1, 10, 1},
+		{0, 1, 1},
+		{-1, 1, 1},
+		{0, 0, 1},
+		{0, 1 << 20, runtime.NumCPU()},
+		{-3, 1 << 20, runtime.NumCPU()},
+	}
+
+	for _, tt := range tests {
+		if got := jobCount(tt.n, tt.files); got != tt.expected {
+			t.Errorf("jobCount(%d, %d) = %d, want %d", tt.n, tt.files, got, tt.expected)
+		}
+	}
+}
+
+func TestForEachOrdered(t *testing.T) {
+	var files []string
+	for i := range 8 {
+		files = append(files, strconv.Itoa(i))
+	}
+
+	for _, jobs := range []int{-1, 0, 1, 3, 8, 100} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			// 靠后的文件先完成，编号为 3 的倍数的文件处理失败
+			var mu sync.Mutex
+			var finished []string
+			process := func(file string) fileResult {
+				i, _ := strconv.Atoi(file)
+				time.Sleep(time.Duration(len(files)-i) * 5 * time.Millisecond)
+				mu.Lock()
+				finished = append(finished, file)
+				mu.Unlock()
+				if i%3 == 0 {
+					return fileResult{err: errors.New("fail " + file)}
+				}
+				return fileResult{output: "ok " + file}
+			}
+
+			var order, outputs, errs []string
+			forEachOrdered(files, jobs, process, func(i int, file string, res fileResult) {
+				if files[i] != file {
+					t.Errorf("emit(%d, %q), want file %q", i, file, files[i])
+				}
+				order = append(order, file)
+				if res.err != nil {
+					errs = append(errs, res.err.Error())
+					return
+				}
+				outputs = append(outputs, res.output)
+			})
+
+			if jobs >= len(files) && slices.Equal(finished, files) {
+				t.Errorf("finish order = %q, want out of order", finished)
+			}
+			if !slices.Equal(order, files) {
+				t.Errorf("emit order = %q, want %q", order, files)
+			}
+			if want := []string{"ok 1", "ok 2", "ok 4", "ok 5", "ok 7"}; !slices.Equal(outputs, want) {
+				t.Errorf("outputs = %q, want %q", outputs, want)
+			}
+			if want := []string{"fail 0", "fail 3", "fail 6"}; !slices.Equal(errs, want) {
+				t.Errorf("errors = %q, want %q", errs, want)
+			}
+		})
+	}
+
+	// 没有文件时不调用 process 和 emit
+	forEachOrdered(nil, 0, func(string) fileResult {
+		t.Error("process called without files")
+		return fileResult{}
+	}, func(int, string, fileResult) {
+		t.Error("emit called without files")
+	})
+}
@@ -0,0 +1,316 @@
+package mdtoc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/lwmacct/251202-mc-mdtoc/internal/config"
+	"github.com/lwmacct/251202-mc-mdtoc/internal/fileset"
+	"github.com/lwmacct/251202-mc-mdtoc/internal/gitrepo"
+	"github.com/lwmacct/251202-mc-mdtoc/internal/mdtoc"
+	"github.com/urfave/cli/v3"
+)
+
+// resolver 为每个文件解析生效的选项
+// 优先级：命令行显式指定 > 配置文件 overrides > 配置文件顶层设置 > 命令行默认值
+type resolver struct {
+	defaults config.Settings // 命令行默认值
+	flags    config.Settings // 命令行显式指定的值
+	include  []string        // 命令行指定的包含模式
+	exclude  []string        // 命令行指定的排除模式
+	format   string          // TOC 渲染格式 (markdown/html/mermaid)
+	class    string          // HTML 格式的 CSS 类名前缀
+	loader   *config.Loader
+
+	mu        sync.Mutex
+	templates map[string]*mdtoc.Template // 已加载的模板 (按路径缓存)
+	repos     map[string]*gitrepo.Repo   // 目录所在的 git 仓库 (nil 表示不在仓库中)
+}
+
+// newResolver 从命令行参数创建选项解析器
+func newResolver(cmd *cli.Command) *resolver {
+	return &resolver{
+		defaults: settingsFromFlags(cmd, false),
+		flags:    settingsFromFlags(cmd, true),
+		include:  cmd.StringSlice("include"),
+		exclude:  cmd.StringSlice("exclude"),
+		format:   cmd.String("format"),
+		class:    cmd.String("html-class"),
+		loader:   config.NewLoader(),
+
+		templates: make(map[string]*mdtoc.Template),
+		repos:     make(map[string]*gitrepo.Repo),
+	}
+}
+
+// settingsFromFlags 读取命令行中的 TOC 选项
+// onlySet 为 true 时只返回用户显式指定的选项
+func settingsFromFlags(cmd *cli.Command, onlySet bool) config.Settings {
+	var s config.Settings
+
+	intFlag := func(name string) *int {
+		if onlySet && !cmd.IsSet(name) {
+			return nil
+		}
+		v := int(cmd.Int(name))
+		return &v
+	}
+	boolFlag := func(name string) *bool {
+		if onlySet && !cmd.IsSet(name) {
+			return nil
+		}
+		v := cmd.Bool(name)
+		return &v
+	}
+
+	s.MinLevel = intFlag("min-level")
+	s.MaxLevel = intFlag("max-level")
+	s.Ordered = boolFlag("ordered")
+	s.LineNumber = boolFlag("line-number")
+	s.Path = boolFlag("path")
+	s.Global = boolFlag("global")
+	s.Anchor = boolFlag("anchor")
+	s.LineExclusive = boolFlag("line-exclusive")
+	if onlySet && !cmd.IsSet("line-style") {
+		s.LineStyle = nil
+	} else {
+		v := cmd.String("line-style")
+		s.LineStyle = &v
+	}
+
+	// 以下选项没有默认值，只在显式指定时设置
+	stringFlag := func(name string) *string {
+		if !cmd.IsSet(name) {
+			return nil
+		}
+		v := cmd.String(name)
+		return &v
+	}
+	s.ListStyle = stringFlag("list-style")
+	s.Collapse = stringFlag("collapse")
+	s.Summary = stringFlag("summary")
+	s.Slug = stringFlag("slug")
+	s.Template = stringFlag("template")
+	s.Permalink = stringFlag("permalink")
+	s.PermalinkBase = stringFlag("permalink-base")
+	s.PermalinkRef = stringFlag("permalink-ref")
+
+	return s
+}
+
+// settings 返回文件生效的设置及其来源配置 (没有配置文件时为 nil)
+func (r *resolver) settings(file string) (config.Settings, *config.Config, error) {
+	cfg, err := r.loader.ForFile(file)
+	if err != nil {
+		return config.Settings{}, nil, err
+	}
+
+	s := r.defaults
+	if cfg != nil {
+		s = s.Merge(cfg.Resolve(file))
+	}
+	return s.Merge(r.flags), cfg, nil
+}
+
+// options 返回文件生效的 TOC 选项
+func (r *resolver) options(file string) (mdtoc.Options, error) {
+	s, cfg, err := r.settings(file)
+	if err != nil {
+		return mdtoc.Options{}, err
+	}
+
+	var opts mdtoc.Options
+	s.Apply(&opts)
+	if err := validateOptions(opts); err != nil {
+		return mdtoc.Options{}, err
+	}
+
+	// HTML 文档按标签解析标题，写入的 TOC 固定为 HTML 格式
+	if r.format == formatHTML || isHTMLFile(file) {
+		opts.Format = mdtoc.FormatHTML
+		opts.HTMLClass = r.class
+	} else if r.format == formatMermaid {
+		opts.Format = mdtoc.FormatMermaid
+	}
+	opts.HTMLInput = isHTMLFile(file)
+	opts.FilePath = file
+
+	if s.ListStyle != nil {
+		if opts.List, err = mdtoc.ParseListStyle(*s.ListStyle); err != nil {
+			return mdtoc.Options{}, err
+		}
+	}
+	if s.Template != nil && *s.Template != "" {
+		if opts.Template, err = r.template(*s.Template); err != nil {
+			return mdtoc.Options{}, err
+		}
+	}
+	// 过滤模式未指定 --stdin-path 时没有文件路径，不生成永久链接
+	if s.Permalink != nil && *s.Permalink != "" && file != "" {
+		if opts.Permalink, err = r.permalink(file, s, cfg); err != nil {
+			return mdtoc.Options{}, err
+		}
+	}
+	return opts, nil
+}
+
+// permalink 创建文件的永久链接设置
+// 仓库地址和引用优先使用配置，否则从文件所在的 git 仓库读取；
+// 不在 git 仓库中时路径相对于配置文件所在目录
+func (r *resolver) permalink(file string, s config.Settings, cfg *config.Config) (*mdtoc.Permalink, error) {
+	tmpl, err := mdtoc.PermalinkTemplate(*s.Permalink)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &mdtoc.Permalink{Template: tmpl, AbsPath: filepath.ToSlash(abs)}
+	if !p.NeedsRepo() {
+		return p, nil
+	}
+
+	switch repo := r.repo(filepath.Dir(abs)); {
+	case repo != nil:
+		if p.Path, err = repo.Rel(abs); err != nil {
+			return nil, err
+		}
+		p.Base, p.Ref = repo.WebURL, repo.Ref()
+	case cfg != nil:
+		rel, err := filepath.Rel(cfg.Dir(), abs)
+		if err != nil {
+			return nil, err
+		}
+		p.Path = filepath.ToSlash(rel)
+	default:
+		return nil, fmt.Errorf("%s 不在 git 仓库中，无法确定永久链接的仓库路径", file)
+	}
+
+	if s.PermalinkBase != nil && *s.PermalinkBase != "" {
+		p.Base = *s.PermalinkBase
+	}
+	if s.PermalinkRef != nil && *s.PermalinkRef != "" {
+		p.Ref = *s.PermalinkRef
+	}
+	if p.Base == "" && strings.Contains(tmpl, "{base}") {
+		return nil, fmt.Errorf("无法从 git remote origin 推断仓库网页地址，请通过 --permalink-base 或配置 permalink_base 指定")
+	}
+	if p.Ref == "" && strings.Contains(tmpl, "{ref}") {
+		return nil, fmt.Errorf("无法从 origin/HEAD 确定默认分支，请通过 --permalink-ref 或配置 permalink_ref 指定 (或执行 git remote set-head origin --auto)")
+	}
+	return p, nil
+}
+
+// repo 返回目录所在的 git 仓库，同一目录只查询一次
+func (r *resolver) repo(dir string) *gitrepo.Repo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	repo, ok := r.repos[dir]
+	if !ok {
+		repo, _ = gitrepo.Open(dir)
+		r.repos[dir] = repo
+	}
+	return repo
+}
+
+// template 加载模板文件，同一路径只加载一次
+func (r *resolver) template(path string) (*mdtoc.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.templates[path]; ok {
+		return t, nil
+	}
+	t, err := mdtoc.LoadTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+	r.templates[path] = t
+	return t, nil
+}
+
+// newTOC 为文件创建 TOC 实例
+// write 为 true 时强制启用 ShowAnchor（写入文件必须有链接）
+func (r *resolver) newTOC(file string, write bool) (*mdtoc.TOC, error) {
+	opts, err := r.options(file)
+	if err != nil {
+		return nil, err
+	}
+	if write {
+		opts.ShowAnchor = true
+	}
+	return mdtoc.New(opts), nil
+}
+
+// collect 展开路径列表中的目录
+func (r *resolver) collect(paths []string) ([]string, error) {
+	var files []string
+
+	for _, p := range paths {
+		opts := fileset.Options{Include: r.include, Exclude: r.exclude}
+		if info, err := os.Stat(p); err == nil && info.IsDir() {
+			if opts, err = r.dirOptions(p); err != nil {
+				return nil, err
+			}
+		}
+
+		found, err := fileset.Collect([]string{p}, opts)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+
+	// 合并不同参数展开的结果并去重
+	return fileset.Collect(files, fileset.Options{})
+}
+
+// dirOptions 返回遍历目录时的文件收集选项
+// 命令行未指定 --include/--exclude 时使用目录对应配置文件中的模式
+func (r *resolver) dirOptions(dir string) (fileset.Options, error) {
+	opts := fileset.Options{Include: r.include, Exclude: r.exclude}
+	if len(r.include) > 0 || len(r.exclude) > 0 {
+		return opts, nil
+	}
+
+	cfg, err := r.loader.ForDir(dir)
+	if err != nil {
+		return fileset.Options{}, err
+	}
+	if cfg != nil {
+		opts = fileset.Options{Include: cfg.Include, Exclude: cfg.Exclude, Base: cfg.Dir()}
+	}
+	return opts, nil
+}
+
+// validateOptions 验证层级参数
+func validateOptions(opts mdtoc.Options) error {
+	if opts.MinLevel < 1 || opts.MinLevel > 6 {
+		return fmt.Errorf("min-level 必须在 1-6 之间")
+	}
+	if opts.MaxLevel < 1 || opts.MaxLevel > 6 {
+		return fmt.Errorf("max-level 必须在 1-6 之间")
+	}
+	if opts.MinLevel > opts.MaxLevel {
+		return fmt.Errorf("min-level 不能大于 max-level")
+	}
+	switch opts.Collapse {
+	case "", mdtoc.CollapseNone, mdtoc.CollapseDetails, mdtoc.CollapseGroups:
+	default:
+		return fmt.Errorf("不支持的折叠样式 %q (可选: none, details, groups)", opts.Collapse)
+	}
+	if _, ok := mdtoc.Sluggers[opts.Slug]; opts.Slug != "" && !ok {
+		return fmt.Errorf("不支持的锚点规则 %q (可选: %s)", opts.Slug, strings.Join(mdtoc.SlugNames(), ", "))
+	}
+	if opts.LineStyle != "" && !slices.Contains(mdtoc.LineStyles, opts.LineStyle) {
+		return fmt.Errorf("不支持的行号格式 %q (可选: %s)", opts.LineStyle, strings.Join(mdtoc.LineStyles, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package mdtoc
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lwmacct/251202-mc-mdtoc/internal/fileset"
+	"github.com/lwmacct/251202-mc-mdtoc/internal/mdtoc"
+	"github.com/urfave/cli/v3"
+)
+
+// watchCommand 监听文件变化并自动更新 TOC
+var watchCommand = &cli.Command{
+	Name:      "watch",
+	Usage:     "监听 Markdown 文件变化，保存时自动更新 TOC",
+	UsageText: "mc-mdtoc [options] watch [file|dir]...",
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "debounce",
+			Value: 200 * time.Millisecond,
+			Usage: "合并连续变化事件的等待时间",
+		},
+	},
+	Action: watchAction,
+}
+
+func watchAction(ctx context.Context, cmd *cli.Command) error {
+	paths := cmd.Args().Slice()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	r := newResolver(cmd)
+
+	// 验证命令行指定的层级参数
+	var flagOpts mdtoc.Options
+	r.defaults.Merge(r.flags).Apply(&flagOpts)
+	if err := validateOptions(flagOpts); err != nil {
+		return err
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fw.Close()
+
+	// 启动时不修改文件，只处理之后发生变化的文件
+	w := newWatcher(r, paths, fw)
+	if err := w.refresh(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "监听 %d 个文件的变化 (Ctrl+C 退出)\n", len(w.files))
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return w.run(ctx, fw.Events, fw.Errors, cmd.Duration("debounce"))
+}
+
+// watcher 监听目录事件，对变化的 Markdown 文件重新生成 TOC
+type watcher struct {
+	r     *resolver
+	paths []string
+	fw    *fsnotify.Watcher
+
+	files map[string]bool // 纳入处理的文件 (Clean 后的路径)
+	dirs  map[string]bool // 已监听的目录
+
+	// written 记录每个文件最近一次写入内容的哈希
+	// 写入本身会触发事件，内容未被再次修改时据此忽略
+	written map[string][sha256.Size]byte
+
+	// 以下为 debounce 时间内累积的事件，由 flush 统一处理
+	pending map[string]bool // 内容可能变化的文件
+	created map[string]bool // 新建的路径，可能需要重新扫描文件列表
+	rescan  bool            // 纳入处理的文件或目录被删除或重命名
+}
+
+// newWatcher 创建监听器，调用 refresh 后开始生效
+func newWatcher(r *resolver, paths []string, fw *fsnotify.Watcher) *watcher {
+	return &watcher{
+		r:       r,
+		paths:   paths,
+		fw:      fw,
+		dirs:    make(map[string]bool),
+		written: make(map[string][sha256.Size]byte),
+		pending: make(map[string]bool),
+		created: make(map[string]bool),
+	}
+}
+
+// run 事件循环，合并 debounce 时间内的连续事件后批量处理
+func (w *watcher) run(ctx context.Context, events <-chan fsnotify.Event, errs <-chan error, debounce time.Duration) error {
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err, ok := <-errs:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "watch: %v\n", err)
+
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if w.handle(ev) {
+				timer.Reset(debounce)
+			}
+
+		case <-timer.C:
+			w.flush()
+		}
+	}
+}
+
+// handle 记录单个事件，返回事件是否需要处理 (需要重新开始 debounce 计时)
+func (w *watcher) handle(ev fsnotify.Event) bool {
+	name := filepath.Clean(ev.Name)
+	switch {
+	case ev.Has(fsnotify.Create):
+		// 编辑器常以 "写临时文件 + 重命名" 的方式保存，WriteFile 也是如此，
+		// 因此新建的路径到 flush 时才判断是否为新文件或目录
+		w.created[name] = true
+		w.pending[name] = true
+	case ev.Has(fsnotify.Write):
+		w.pending[name] = true
+	case ev.Has(fsnotify.Remove), ev.Has(fsnotify.Rename):
+		// 临时文件被重命名不影响文件列表
+		if w.files[name] || w.dirs[name] {
+			w.rescan = true
+		}
+		delete(w.written, name)
+	default:
+		return false
+	}
+	return true
+}
+
+// flush 处理累积的事件：必要时重新扫描文件列表，再更新变化的文件
+func (w *watcher) flush() {
+	for name := range w.created {
+		if w.isNew(name) {
+			w.rescan = true
+			break
+		}
+	}
+	if w.rescan {
+		if err := w.refresh(); err != nil {
+			fmt.Fprintf(os.Stderr, "watch: %v\n", err)
+		}
+	}
+
+	for _, file := range slices.Sorted(maps.Keys(w.pending)) {
+		if w.files[file] {
+			w.update(file)
+		}
+	}
+	clear(w.pending)
+	clear(w.created)
+	w.rescan = false
+}
+
+// isNew 判断新建的路径是否可能改变文件列表
+// 已纳入处理的文件被替换 (包括自身写入) 以及已不存在的临时文件不需要重新扫描
+func (w *watcher) isNew(name string) bool {
+	info, err := os.Stat(name)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		return !w.dirs[name]
+	}
+	return !w.files[name]
+}
+
+// refresh 重新收集文件列表，并为新出现的目录添加监听
+// 目录的遍历选项与文件收集一致 (命令行或配置文件的 include/exclude)
+func (w *watcher) refresh() error {
+	files, err := w.r.collect(w.paths)
+	if err != nil {
+		return err
+	}
+	w.files = make(map[string]bool, len(files))
+	for _, f := range files {
+		w.files[filepath.Clean(f)] = true
+	}
+
+	for _, p := range w.paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+
+		// 单个文件监听其所在目录，以便捕获编辑器的重命名式保存
+		dirs := []string{filepath.Dir(p)}
+		if info.IsDir() {
+			opts, err := w.r.dirOptions(p)
+			if err != nil {
+				return err
+			}
+			if dirs, err = fileset.Dirs(p, opts); err != nil {
+				return err
+			}
+		}
+
+		for _, dir := range dirs {
+			dir = filepath.Clean(dir)
+			if w.dirs[dir] {
+				continue
+			}
+			if err := w.fw.Add(dir); err != nil {
+				return fmt.Errorf("%s: %w", dir, err)
+			}
+			w.dirs[dir] = true
+		}
+	}
+	return nil
+}
+
+// update 更新单个文件的 TOC，内容无变化时不写入
+func (w *watcher) update(file string) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		// 文件可能在事件到达前已被删除
+		if !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+		}
+		return
+	}
+
+	// 忽略自身写入触发的事件
+	if sum, ok := w.written[file]; ok && sum == sha256.Sum256(content) {
+		return
+	}
+
+	toc, err := w.r.newTOC(file, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+		return
+	}
+
+	newContent, err := toc.UpdateContent(content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+		return
+	}
+	changed, err := mdtoc.WriteFile(file, content, newContent)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+		return
+	}
+	w.written[file] = sha256.Sum256(newContent)
+	if !changed {
+		return
+	}
+	fmt.Printf("%s %s: 已更新\n", time.Now().Format(time.TimeOnly), file)
+}
@@ -0,0 +1,168 @@
+package mdtoc
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// newTestWatcher 监听 dir 并完成首次扫描
+func newTestWatcher(t *testing.T, dir string, args ...string) *watcher {
+	t.Helper()
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fw.Close() })
+
+	w := newWatcher(testResolver(t, args...), []string{dir}, fw)
+	if err := w.refresh(); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+const watchDoc = "# Title\n\n## A\n\n## B\n"
+
+func TestWatcher_StartupKeepsFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.md")
+	writeFile(t, file, watchDoc)
+
+	w := newTestWatcher(t, dir)
+	if !w.files[file] {
+		t.Fatalf("files = %v, want %s", w.files, file)
+	}
+	// 启动扫描不修改文件
+	if got := readFile(t, file); got != watchDoc {
+		t.Errorf("file changed on startup:\n%s", got)
+	}
+}
+
+func TestWatcher_SelfWrite(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.md")
+	writeFile(t, file, watchDoc)
+	w := newTestWatcher(t, dir)
+
+	w.handle(fsnotify.Event{Name: file, Op: fsnotify.Write})
+	w.flush()
+	updated := readFile(t, file)
+	if !strings.Contains(updated, "- [A](#a)") {
+		t.Fatalf("file not updated:\n%s", updated)
+	}
+
+	// 改用有序列表：之后如果再次处理该文件，内容会变化
+	w.r = testResolver(t, "--ordered")
+	// 目录中新出现的文件只有重新扫描时才会被纳入
+	other := filepath.Join(dir, "b.md")
+	writeFile(t, other, watchDoc)
+
+	// WriteFile 的 "临时文件 + 重命名" 产生的事件
+	tmp := filepath.Join(dir, ".a.md.123.tmp")
+	for _, ev := range []fsnotify.Event{
+		{Name: tmp, Op: fsnotify.Create},
+		{Name: tmp, Op: fsnotify.Write},
+		{Name: tmp, Op: fsnotify.Rename},
+		{Name: file, Op: fsnotify.Create},
+	} {
+		w.handle(ev)
+	}
+	w.flush()
+
+	if got := readFile(t, file); got != updated {
+		t.Errorf("self-written file processed again:\n%s", got)
+	}
+	if w.files[other] {
+		t.Error("self-written file should not trigger rescan")
+	}
+
+	// 外部修改后重新处理
+	writeFile(t, file, updated+"\n## C\n")
+	w.handle(fsnotify.Event{Name: file, Op: fsnotify.Write})
+	w.flush()
+	if got := readFile(t, file); !strings.Contains(got, "1. [A](#a)") || !strings.Contains(got, "3. [C](#c)") {
+		t.Errorf("modified file not updated:\n%s", got)
+	}
+}
+
+func TestWatcher_CreateRescan(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.md"), watchDoc)
+	writeFile(t, filepath.Join(dir, ".mdtoc.yaml"), "exclude: [drafts]\n")
+	w := newTestWatcher(t, dir)
+
+	// 新建的目录和文件
+	sub := filepath.Join(dir, "sub")
+	file := filepath.Join(sub, "new.md")
+	writeFile(t, file, watchDoc)
+	w.handle(fsnotify.Event{Name: sub, Op: fsnotify.Create})
+	w.flush()
+	if !w.dirs[sub] {
+		t.Errorf("dirs = %v, want %s watched", w.dirs, sub)
+	}
+
+	w.handle(fsnotify.Event{Name: file, Op: fsnotify.Create})
+	w.flush()
+	if !w.files[file] {
+		t.Fatalf("files = %v, want %s", w.files, file)
+	}
+	if got := readFile(t, file); !strings.Contains(got, "- [A](#a)") {
+		t.Errorf("created file not updated:\n%s", got)
+	}
+
+	// 重新扫描与首次收集一样遵循配置文件的 exclude
+	drafts := filepath.Join(dir, "drafts")
+	writeFile(t, filepath.Join(drafts, "d.md"), watchDoc)
+	w.handle(fsnotify.Event{Name: drafts, Op: fsnotify.Create})
+	w.flush()
+	if w.dirs[drafts] {
+		t.Errorf("excluded directory %s should not be watched", drafts)
+	}
+	if w.files[filepath.Join(drafts, "d.md")] {
+		t.Errorf("excluded file should not be collected")
+	}
+
+	// 不相关的事件不触发处理
+	if w.handle(fsnotify.Event{Name: file, Op: fsnotify.Chmod}) {
+		t.Error("handle(Chmod) = true, want false")
+	}
+}
+
+func TestWatcher_Debounce(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.md")
+	writeFile(t, file, watchDoc)
+	w := newTestWatcher(t, dir)
+
+	const debounce = 300 * time.Millisecond
+	events := make(chan fsnotify.Event)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.run(ctx, events, nil, debounce) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// 每个事件重新开始计时，连续事件结束前不处理
+	events <- fsnotify.Event{Name: file, Op: fsnotify.Write}
+	time.Sleep(debounce / 2)
+	events <- fsnotify.Event{Name: file, Op: fsnotify.Write}
+	time.Sleep(debounce * 3 / 4)
+	if got := readFile(t, file); got != watchDoc {
+		t.Fatalf("file updated before debounce elapsed:\n%s", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for readFile(t, file) == watchDoc {
+		if time.Now().After(deadline) {
+			t.Fatal("file not updated after debounce")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,314 @@
+// Package config 加载 mc-mdtoc 项目配置文件 (.mdtoc.yaml)
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lwmacct/251202-mc-mdtoc/internal/fileset"
+	"github.com/lwmacct/251202-mc-mdtoc/internal/mdtoc"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName 是项目配置文件名
+const FileName = ".mdtoc.yaml"
+
+// Settings 表示可以由配置文件和命令行设置的 TOC 选项
+// 字段为 nil 表示未设置，合并时不会覆盖其他来源的值
+type Settings struct {
+	MinLevel   *int  `yaml:"min_level,omitempty"`   // 最小标题层级
+	MaxLevel   *int  `yaml:"max_level,omitempty"`   // 最大标题层级
+	Ordered    *bool `yaml:"ordered,omitempty"`     // 使用有序列表
+	LineNumber *bool `yaml:"line_number,omitempty"` // 显示行号范围
+	Path       *bool `yaml:"path,omitempty"`        // 显示文件路径
+	Global     *bool `yaml:"global,omitempty"`      // 全局模式
+	Anchor     *bool `yaml:"anchor,omitempty"`      // 预览时显示锚点链接
+
+	LineStyle     *string `yaml:"line_style,omitempty"`     // 行号范围格式
+	LineExclusive *bool   `yaml:"line_exclusive,omitempty"` // 父标题行号范围不包含子标题内容
+	ListStyle     *string `yaml:"list_style,omitempty"`     // 列表格式 (配置名和选项，如 prettier,bullet=*)
+	Collapse      *string `yaml:"collapse,omitempty"`       // 折叠样式: none | details | groups
+	Summary       *string `yaml:"summary,omitempty"`        // 折叠时 <summary> 的文本
+	Slug          *string `yaml:"slug,omitempty"`           // 锚点生成规则 (github/gitlab/vitepress/hugo/pandoc 等)
+
+	// Template 自定义 TOC 模板文件路径
+	// 配置文件中的相对路径在加载时转换为相对于配置文件所在目录的路径
+	Template *string `yaml:"template,omitempty"`
+
+	// Permalink 行号范围链接的平台 (github/gitlab/gitea/file) 或 URL 模板
+	// PermalinkBase / PermalinkRef 为仓库网页地址和引用，未设置时从本地 git 仓库读取
+	Permalink     *string `yaml:"permalink,omitempty"`
+	PermalinkBase *string `yaml:"permalink_base,omitempty"`
+	PermalinkRef  *string `yaml:"permalink_ref,omitempty"`
+}
+
+// Merge 合并设置，other 中已设置的字段覆盖当前值
+func (s Settings) Merge(other Settings) Settings {
+	if other.MinLevel != nil {
+		s.MinLevel = other.MinLevel
+	}
+	if other.MaxLevel != nil {
+		s.MaxLevel = other.MaxLevel
+	}
+	if other.Ordered != nil {
+		s.Ordered = other.Ordered
+	}
+	if other.LineNumber != nil {
+		s.LineNumber = other.LineNumber
+	}
+	if other.Path != nil {
+		s.Path = other.Path
+	}
+	if other.Global != nil {
+		s.Global = other.Global
+	}
+	if other.Anchor != nil {
+		s.Anchor = other.Anchor
+	}
+	if other.LineStyle != nil {
+		s.LineStyle = other.LineStyle
+	}
+	if other.LineExclusive != nil {
+		s.LineExclusive = other.LineExclusive
+	}
+	if other.ListStyle != nil {
+		s.ListStyle = other.ListStyle
+	}
+	if other.Collapse != nil {
+		s.Collapse = other.Collapse
+	}
+	if other.Summary != nil {
+		s.Summary = other.Summary
+	}
+	if other.Slug != nil {
+		s.Slug = other.Slug
+	}
+	if other.Template != nil {
+		s.Template = other.Template
+	}
+	if other.Permalink != nil {
+		s.Permalink = other.Permalink
+	}
+	if other.PermalinkBase != nil {
+		s.PermalinkBase = other.PermalinkBase
+	}
+	if other.PermalinkRef != nil {
+		s.PermalinkRef = other.PermalinkRef
+	}
+	return s
+}
+
+// Apply 将已设置的字段写入 mdtoc.Options
+func (s Settings) Apply(opts *mdtoc.Options) {
+	if s.MinLevel != nil {
+		opts.MinLevel = *s.MinLevel
+	}
+	if s.MaxLevel != nil {
+		opts.MaxLevel = *s.MaxLevel
+	}
+	if s.Ordered != nil {
+		opts.Ordered = *s.Ordered
+	}
+	if s.LineNumber != nil {
+		opts.LineNumber = *s.LineNumber
+	}
+	if s.Path != nil {
+		opts.ShowPath = *s.Path
+	}
+	if s.Global != nil {
+		opts.SectionTOC = !*s.Global
+	}
+	if s.Anchor != nil {
+		opts.ShowAnchor = *s.Anchor
+	}
+	if s.LineStyle != nil {
+		opts.LineStyle = *s.LineStyle
+	}
+	if s.LineExclusive != nil {
+		opts.LineExclusive = *s.LineExclusive
+	}
+	if s.Collapse != nil {
+		opts.Collapse = *s.Collapse
+	}
+	if s.Summary != nil {
+		opts.Summary = *s.Summary
+	}
+	if s.Slug != nil {
+		opts.Slug = *s.Slug
+	}
+}
+
+// resolvePaths 将相对路径转换为基于 dir 的路径
+func (s *Settings) resolvePaths(dir string) {
+	if s.Template != nil && *s.Template != "" && !filepath.IsAbs(*s.Template) {
+		p := filepath.Join(dir, *s.Template)
+		s.Template = &p
+	}
+}
+
+// Override 表示按路径覆盖的设置块
+type Override struct {
+	Paths    []string `yaml:"paths"` // glob 模式 (相对于配置文件所在目录)
+	Settings `yaml:",inline"`
+}
+
+// Config 表示 .mdtoc.yaml 配置文件
+type Config struct {
+	Settings  `yaml:",inline"`
+	Include   []string   `yaml:"include,omitempty"`   // 遍历目录时包含的 glob 模式
+	Exclude   []string   `yaml:"exclude,omitempty"`   // 遍历目录时排除的 glob 模式
+	Overrides []Override `yaml:"overrides,omitempty"` // 按路径覆盖的设置，按顺序应用
+
+	path string // 配置文件路径
+}
+
+// Path 返回配置文件路径
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Dir 返回配置文件所在目录，路径模式相对于此目录匹配
+func (c *Config) Dir() string {
+	return filepath.Dir(c.path)
+}
+
+// Resolve 返回文件生效的设置
+// 先应用顶层设置，再按顺序应用匹配的 overrides
+func (c *Config) Resolve(file string) Settings {
+	result := c.Settings
+
+	rel, err := c.relPath(file)
+	if err != nil {
+		return result
+	}
+
+	for _, o := range c.Overrides {
+		if fileset.Match(o.Paths, rel) {
+			result = result.Merge(o.Settings)
+		}
+	}
+	return result
+}
+
+// relPath 返回文件相对于配置文件目录的 slash 路径
+func (c *Config) relPath(file string) (string, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(c.Dir(), abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Load 加载配置文件
+// 未知字段会返回错误，避免拼写错误被静默忽略
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{path: abs}
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// 模板路径相对于配置文件所在目录
+	cfg.Settings.resolvePaths(cfg.Dir())
+	for i := range cfg.Overrides {
+		cfg.Overrides[i].Settings.resolvePaths(cfg.Dir())
+	}
+
+	return cfg, nil
+}
+
+// Find 从目录开始向上查找配置文件
+// 返回找到的配置文件路径，未找到时返回空字符串
+func Find(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(abs, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", nil
+		}
+		abs = parent
+	}
+}
+
+// Loader 按目录缓存已加载的配置，可并发使用
+type Loader struct {
+	mu      sync.Mutex
+	dirs    map[string]*Config // 目录 -> 生效的配置 (nil 表示没有配置文件)
+	configs map[string]*Config // 配置文件路径 -> 配置
+}
+
+// NewLoader 创建新的配置加载器
+func NewLoader() *Loader {
+	return &Loader{
+		dirs:    make(map[string]*Config),
+		configs: make(map[string]*Config),
+	}
+}
+
+// ForFile 返回文件生效的配置 (从文件所在目录向上查找)
+// 没有配置文件时返回 nil, nil
+func (l *Loader) ForFile(file string) (*Config, error) {
+	return l.ForDir(filepath.Dir(file))
+}
+
+// ForDir 返回目录生效的配置 (从该目录向上查找)
+// 没有配置文件时返回 nil, nil
+func (l *Loader) ForDir(dir string) (*Config, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if cfg, ok := l.dirs[abs]; ok {
+		return cfg, nil
+	}
+
+	path, err := Find(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *Config
+	if path != "" {
+		if cfg = l.configs[path]; cfg == nil {
+			if cfg, err = Load(path); err != nil {
+				return nil, err
+			}
+			l.configs[path] = cfg
+		}
+	}
+
+	l.dirs[abs] = cfg
+	return cfg, nil
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lwmacct/251202-mc-mdtoc/internal/mdtoc"
+)
+
+func intPtr(v int) *int    { return &v }
+func boolPtr(v bool) *bool { return &v }
+
+// writeConfig 在目录中写入配置文件
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("full config", func(t *testing.T) {
+		path := writeConfig(t, filepath.Join(dir, "full"), `
+min_level: 2
+max_level: 4
+ordered: true
+line_number: false
+include: ["*.md", "*.markdown"]
+exclude: [drafts]
+overrides:
+  - paths: ["docs/design/**"]
+    global: true
+`)
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cfg.MinLevel == nil || *cfg.MinLevel != 2 {
+			t.Errorf("MinLevel = %v, want 2", cfg.MinLevel)
+		}
+		if cfg.MaxLevel == nil || *cfg.MaxLevel != 4 {
+			t.Errorf("MaxLevel = %v, want 4", cfg.MaxLevel)
+		}
+		if cfg.Ordered == nil || !*cfg.Ordered {
+			t.Errorf("Ordered = %v, want true", cfg.Ordered)
+		}
+		if cfg.LineNumber == nil || *cfg.LineNumber {
+			t.Errorf("LineNumber = %v, want false", cfg.LineNumber)
+		}
+		if cfg.Global != nil {
+			t.Errorf("Global should be unset, got %v", *cfg.Global)
+		}
+		if len(cfg.Include) != 2 || len(cfg.Exclude) != 1 {
+			t.Errorf("Include = %v, Exclude = %v", cfg.Include, cfg.Exclude)
+		}
+		if len(cfg.Overrides) != 1 || cfg.Overrides[0].Global == nil || !*cfg.Overrides[0].Global {
+			t.Errorf("Overrides = %+v", cfg.Overrides)
+		}
+		if cfg.Path() != path {
+			t.Errorf("Path() = %q, want %q", cfg.Path(), path)
+		}
+	})
+
+	t.Run("empty config", func(t *testing.T) {
+		path := writeConfig(t, filepath.Join(dir, "empty"), "")
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.MinLevel != nil || len(cfg.Overrides) != 0 {
+			t.Errorf("empty config should have no settings, got %+v", cfg)
+		}
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		path := writeConfig(t, filepath.Join(dir, "unknown"), "max_levle: 2\n")
+		if _, err := Load(path); err == nil {
+			t.Error("Load() should fail on unknown field")
+		}
+	})
+
+	t.Run("not exists", func(t *testing.T) {
+		if _, err := Load(filepath.Join(dir, "missing", FileName)); err == nil {
+			t.Error("Load() should fail for missing file")
+		}
+	})
+}
+
+func TestLoad_TemplatePath(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, `
+template: tmpl/toc.tmpl
+overrides:
+  - paths: ["docs/**"]
+    template: /abs/docs.tmpl
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 相对路径基于配置文件所在目录，绝对路径保持不变
+	if want := filepath.Join(dir, "tmpl", "toc.tmpl"); cfg.Template == nil || *cfg.Template != want {
+		t.Errorf("Template = %v, want %s", cfg.Template, want)
+	}
+	if got := cfg.Overrides[0].Template; got == nil || *got != "/abs/docs.tmpl" {
+		t.Errorf("Overrides[0].Template = %v, want /abs/docs.tmpl", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	path := writeConfig(t, root, "max_level: 2\n")
+	nested := filepath.Join(root, "a", "b", "c")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Find(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != path {
+		t.Errorf("Find() = %q, want %q", got, path)
+	}
+
+	// 更近的配置文件优先
+	nearer := writeConfig(t, filepath.Join(root, "a"), "max_level: 3\n")
+	got, err = Find(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != nearer {
+		t.Errorf("Find() = %q, want %q", got, nearer)
+	}
+}
+
+func TestConfig_Resolve(t *testing.T) {
+	root := t.TempDir()
+	path := writeConfig(t, root, `
+max_level: 4
+overrides:
+  - paths: ["docs/design/**"]
+    global: true
+  - paths: ["CHANGELOG.md"]
+    max_level: 2
+  - paths: ["docs/design/legacy.md"]
+    global: false
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		maxLevel int
+		global   *bool
+	}{
+		{"top level only", "README.md", 4, nil},
+		{"basename override", "CHANGELOG.md", 2, nil},
+		{"directory override", "docs/design/cmd.md", 4, boolPtr(true)},
+		{"later override wins", "docs/design/legacy.md", 4, boolPtr(false)},
+		{"outside override", "docs/guide.md", 4, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := cfg.Resolve(filepath.Join(root, filepath.FromSlash(tt.file)))
+			if s.MaxLevel == nil || *s.MaxLevel != tt.maxLevel {
+				t.Errorf("MaxLevel = %v, want %d", s.MaxLevel, tt.maxLevel)
+			}
+			if (s.Global == nil) != (tt.global == nil) || (s.Global != nil && *s.Global != *tt.global) {
+				t.Errorf("Global = %v, want %v", s.Global, tt.global)
+			}
+		})
+	}
+}
+
+func TestSettings_MergeAndApply(t *testing.T) {
+	base := Settings{MinLevel: intPtr(1), MaxLevel: intPtr(3), LineNumber: boolPtr(true)}
+	override := Settings{MaxLevel: intPtr(2), Global: boolPtr(true), Anchor: boolPtr(true)}
+
+	merged := base.Merge(override)
+
+	opts := mdtoc.DefaultOptions()
+	merged.Apply(&opts)
+
+	if opts.MinLevel != 1 {
+		t.Errorf("MinLevel = %d, want 1", opts.MinLevel)
+	}
+	if opts.MaxLevel != 2 {
+		t.Errorf("MaxLevel = %d, want 2", opts.MaxLevel)
+	}
+	if !opts.LineNumber {
+		t.Error("LineNumber should be kept from base")
+	}
+	if opts.SectionTOC {
+		t.Error("Global=true should disable SectionTOC")
+	}
+	if !opts.ShowAnchor {
+		t.Error("ShowAnchor should be true")
+	}
+
+	// 未设置的字段不修改原有值
+	opts = mdtoc.Options{Ordered: true}
+	Settings{}.Apply(&opts)
+	if !opts.Ordered {
+		t.Error("Apply() with empty settings should not modify options")
+	}
+}
+
+func TestLoader_ForFile(t *testing.T) {
+	root := t.TempDir()
+	writeConfig(t, root, "max_level: 2\n")
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLoader()
+	a, err := l.ForFile(filepath.Join(root, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := l.ForFile(filepath.Join(root, "docs", "guide.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == nil || a != b {
+		t.Error("ForFile() should return the same cached config for files sharing a config")
+	}
+}
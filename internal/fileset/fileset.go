@@ -0,0 +1,217 @@
+// Package fileset 收集需要处理的 Markdown 文件
+package fileset
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultInclude 是遍历目录时默认包含的文件模式
+var DefaultInclude = []string{"*.md"}
+
+// DefaultSkipDirs 是遍历目录时始终跳过的目录
+var DefaultSkipDirs = []string{".git", "node_modules", "**/.vitepress/dist", "**/.vitepress/cache"}
+
+// Options 配置文件收集选项
+type Options struct {
+	Include []string // 包含的 glob 模式，为空时使用 DefaultInclude
+	Exclude []string // 排除的 glob 模式，匹配的目录会被整体跳过
+	Base    string   // 模式匹配的基准目录，为空时使用遍历的目录
+}
+
+// Collect 展开路径列表
+// 文件路径原样保留 (不受 Include/Exclude 影响)，目录会被递归遍历
+// 结果按输入顺序排列并去重 (指向同一文件的符号链接只保留第一个)
+func Collect(paths []string, opts Options) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+
+	add := func(file string) {
+		key := filepath.Clean(file)
+		if resolved, err := filepath.EvalSymlinks(file); err == nil {
+			key = resolved
+		}
+		if !seen[key] {
+			seen[key] = true
+			files = append(files, file)
+		}
+	}
+
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil || !info.IsDir() {
+			// 不存在的路径交给调用方报告错误
+			add(p)
+			continue
+		}
+
+		found, err := Walk(p, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range found {
+			add(f)
+		}
+	}
+
+	return files, nil
+}
+
+// Walk 递归遍历目录，返回匹配的文件列表 (按字典序)
+// 会遵循目录及其上级目录 (直到 git 仓库根目录) 中的 .gitignore 规则
+func Walk(root string, opts Options) ([]string, error) {
+	var files []string
+	err := walk(root, opts, func(p string, isDir bool) {
+		if !isDir {
+			files = append(files, p)
+		}
+	})
+	return files, err
+}
+
+// Dirs 递归遍历目录，返回未被跳过的目录列表 (含 root 本身，按字典序)
+// 跳过规则与 Walk 一致，用于监听目录变化
+func Dirs(root string, opts Options) ([]string, error) {
+	var dirs []string
+	err := walk(root, opts, func(p string, isDir bool) {
+		if isDir {
+			dirs = append(dirs, p)
+		}
+	})
+	return dirs, err
+}
+
+// walk 遍历目录，对未被跳过的目录和匹配的文件调用 visit
+func walk(root string, opts Options, visit func(p string, isDir bool)) error {
+	include := opts.Include
+	if len(include) == 0 {
+		include = DefaultInclude
+	}
+
+	// .gitignore 规则使用绝对路径匹配
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	ignores, err := loadParentGitignores(absRoot)
+	if err != nil {
+		return err
+	}
+
+	// Include/Exclude 模式相对于 Base 匹配
+	absBase := absRoot
+	if opts.Base != "" {
+		if absBase, err = filepath.Abs(opts.Base); err != nil {
+			return err
+		}
+	}
+
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		abs := filepath.Join(absRoot, rel)
+		if rel == "." {
+			// 遍历的根目录本身不参与过滤
+			rel = ""
+		} else if rel, err = filepath.Rel(absBase, abs); err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel != "" && (Match(DefaultSkipDirs, rel) || Match(opts.Exclude, rel) || isIgnored(ignores, abs, true)) {
+				return filepath.SkipDir
+			}
+			// 加载当前目录的 .gitignore，对其下所有路径生效
+			gi, err := LoadGitignore(abs)
+			if err != nil {
+				return err
+			}
+			if gi != nil {
+				ignores = append(ignores, gi)
+			}
+			visit(p, true)
+			return nil
+		}
+
+		if !Match(include, rel) || Match(opts.Exclude, rel) || isIgnored(ignores, abs, false) {
+			return nil
+		}
+		visit(p, false)
+		return nil
+	})
+}
+
+// Match 检查相对路径是否匹配任一 glob 模式
+// 不含 / 的模式只匹配文件名 (与 .gitignore 的行为一致)
+func Match(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		target := rel
+		if !strings.Contains(p, "/") {
+			target = path.Base(rel)
+		}
+		if matchPattern(p, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIgnored 检查路径是否被 .gitignore 忽略
+// ignores 按目录层级由浅到深排列，深层 .gitignore 的规则优先
+func isIgnored(ignores []*Gitignore, p string, isDir bool) bool {
+	ignored := false
+	for _, gi := range ignores {
+		if matched, result := gi.match(p, isDir); matched {
+			ignored = result
+		}
+	}
+	return ignored
+}
+
+// loadParentGitignores 加载 root 上级目录中的 .gitignore (从 git 仓库根目录开始)
+// root 必须为绝对路径；root 本身是仓库根目录或不在 git 仓库中时返回空列表
+func loadParentGitignores(root string) ([]*Gitignore, error) {
+	if _, err := os.Stat(filepath.Join(root, ".git")); err == nil {
+		return nil, nil
+	}
+
+	// 向上查找包含 .git 的目录
+	var parents []string
+	gitRoot := ""
+	for dir := filepath.Dir(root); ; dir = filepath.Dir(dir) {
+		parents = append(parents, dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			gitRoot = dir
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	if gitRoot == "" {
+		return nil, nil
+	}
+
+	// 从仓库根目录开始加载，保证上层规则在前
+	var ignores []*Gitignore
+	for i := len(parents) - 1; i >= 0; i-- {
+		gi, err := LoadGitignore(parents[i])
+		if err != nil {
+			return nil, err
+		}
+		if gi != nil {
+			ignores = append(ignores, gi)
+		}
+	}
+	return ignores, nil
+}
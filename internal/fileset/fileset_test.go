@@ -0,0 +1,203 @@
+package fileset
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree 在临时目录中创建文件树
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// relPaths 将结果转换为相对于 root 的 slash 路径
+func relPaths(t *testing.T, root string, files []string) []string {
+	t.Helper()
+	var result []string
+	for _, f := range files {
+		rel, err := filepath.Rel(root, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, filepath.ToSlash(rel))
+	}
+	return result
+}
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".git/HEAD":                         "ref: refs/heads/main",
+		".gitignore":                        "ignored/\n*.draft.md\n",
+		"README.md":                         "# Readme",
+		"main.go":                           "package main",
+		"docs/guide.md":                     "# Guide",
+		"docs/post.draft.md":                "# Draft",
+		"docs/api/index.md":                 "# API",
+		"docs/.gitignore":                   "generated.md\n",
+		"docs/generated.md":                 "# Generated",
+		"docs/.vitepress/dist/index.md":     "# Dist",
+		"docs/.vitepress/config.md":         "# Config",
+		"node_modules/pkg/README.md":        "# Pkg",
+		"ignored/a.md":                      "# Ignored",
+		"vendor/lib/README.md":              "# Vendor",
+		"vendor/lib/CHANGELOG.markdown":     "# Changelog",
+		"docs/api/internal/secret/index.md": "# Secret",
+	})
+
+	tests := []struct {
+		name     string
+		root     string
+		opts     Options
+		expected []string
+	}{
+		{
+			name: "default options",
+			root: root,
+			expected: []string{
+				"README.md",
+				"docs/.vitepress/config.md",
+				"docs/api/index.md",
+				"docs/api/internal/secret/index.md",
+				"docs/guide.md",
+				"vendor/lib/README.md",
+			},
+		},
+		{
+			name: "exclude directories",
+			root: root,
+			opts: Options{Exclude: []string{"vendor", "docs/api/internal"}},
+			expected: []string{
+				"README.md",
+				"docs/.vitepress/config.md",
+				"docs/api/index.md",
+				"docs/guide.md",
+			},
+		},
+		{
+			name: "custom include",
+			root: root,
+			opts: Options{Include: []string{"*.markdown", "docs/*.md"}},
+			expected: []string{
+				"docs/guide.md",
+				"vendor/lib/CHANGELOG.markdown",
+			},
+		},
+		{
+			name: "subdirectory honors parent gitignore",
+			root: filepath.Join(root, "docs"),
+			expected: []string{
+				"docs/.vitepress/config.md",
+				"docs/api/index.md",
+				"docs/api/internal/secret/index.md",
+				"docs/guide.md",
+			},
+		},
+		{
+			name: "patterns relative to base",
+			root: filepath.Join(root, "docs"),
+			opts: Options{Exclude: []string{"docs/api"}, Base: root},
+			expected: []string{
+				"docs/.vitepress/config.md",
+				"docs/guide.md",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := Walk(tt.root, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := relPaths(t, root, files)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Walk() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDirs(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".git/HEAD":                     "ref: refs/heads/main",
+		".gitignore":                    "ignored/\n",
+		"docs/guide.md":                 "# Guide",
+		"docs/api/index.md":             "# API",
+		"docs/.vitepress/dist/index.md": "# Dist",
+		"node_modules/pkg/README.md":    "# Pkg",
+		"ignored/a.md":                  "# Ignored",
+		"vendor/lib/README.md":          "# Vendor",
+	})
+
+	dirs, err := Dirs(root, Options{Exclude: []string{"vendor"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 跳过规则与 Walk 一致，不含任何 Markdown 文件的目录也会返回
+	expected := []string{".", "docs", "docs/.vitepress", "docs/api"}
+	if got := relPaths(t, root, dirs); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Dirs() = %v, want %v", got, expected)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.md":       "# A",
+		"dir/b.md":   "# B",
+		"dir/c.md":   "# C",
+		"dir/d.txt":  "text",
+		"other/e.md": "# E",
+	})
+
+	files, err := Collect([]string{
+		filepath.Join(root, "dir", "c.md"),
+		filepath.Join(root, "dir"),
+		filepath.Join(root, "dir", "d.txt"),
+		filepath.Join(root, "missing.md"),
+		filepath.Join(root, "a.md"),
+	}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 文件参数原样保留，目录展开，重复项只保留第一次出现的位置
+	expected := []string{"dir/c.md", "dir/b.md", "dir/d.txt", "missing.md", "a.md"}
+	if got := relPaths(t, root, files); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Collect() = %v, want %v", got, expected)
+	}
+}
+
+func TestCollect_Symlink(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"docs/readme.md": "# Readme",
+	})
+	if err := os.Symlink(filepath.Join("docs", "readme.md"), filepath.Join(root, "README.md")); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+
+	files, err := Collect([]string{root}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 指向同一文件的符号链接只保留一个
+	if len(files) != 1 {
+		t.Errorf("Collect() = %v, want a single file", files)
+	}
+}
@@ -0,0 +1,133 @@
+package fileset
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ignoreRule 表示 .gitignore 中的一条规则
+type ignoreRule struct {
+	pattern string // 匹配模式 (相对于 .gitignore 所在目录)
+	negate  bool   // 以 ! 开头的否定规则
+	dirOnly bool   // 以 / 结尾，只匹配目录
+}
+
+// Gitignore 是单个 .gitignore 文件的匹配器
+type Gitignore struct {
+	dir   string // .gitignore 所在目录
+	rules []ignoreRule
+}
+
+// LoadGitignore 加载目录下的 .gitignore 文件
+// 文件不存在时返回 nil, nil
+func LoadGitignore(dir string) (*Gitignore, error) {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ParseGitignore(dir, lines), nil
+}
+
+// ParseGitignore 解析 .gitignore 规则
+// 规则 (参考 git-scm.com/docs/gitignore):
+// 1. 空行和 # 开头的行被忽略
+// 2. ! 开头表示否定规则
+// 3. / 结尾只匹配目录
+// 4. 不含 / 的模式匹配任意层级，含 / 的模式相对于 .gitignore 所在目录
+func ParseGitignore(dir string, lines []string) *Gitignore {
+	g := &Gitignore{dir: dir}
+
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		line = strings.TrimRight(line, " ")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// 包含 / 的模式锚定到 .gitignore 所在目录，否则匹配任意层级
+		if strings.Contains(line, "/") {
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = "**/" + line
+		}
+		rule.pattern = line
+
+		g.rules = append(g.rules, rule)
+	}
+
+	return g
+}
+
+// Match 检查路径是否被忽略
+// name 为文件系统路径，isDir 表示该路径是否为目录
+// 最后一条匹配的规则生效
+func (g *Gitignore) Match(name string, isDir bool) bool {
+	_, ignored := g.match(name, isDir)
+	return ignored
+}
+
+// match 返回是否有规则匹配以及匹配后的忽略状态
+func (g *Gitignore) match(name string, isDir bool) (matched, ignored bool) {
+	rel, err := filepath.Rel(g.dir, name)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false, false
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, rule := range g.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if matchPattern(rule.pattern, rel) {
+			matched = true
+			ignored = !rule.negate
+		}
+	}
+	return matched, ignored
+}
+
+// matchPattern 使用 doublestar 匹配 glob 模式
+// 末尾的 /** 也匹配目录自身
+func matchPattern(pattern, name string) bool {
+	if ok, _ := doublestar.Match(pattern, name); ok {
+		return true
+	}
+	if base, ok := strings.CutSuffix(pattern, "/**"); ok {
+		matched, _ := doublestar.Match(base, name)
+		return matched
+	}
+	return false
+}
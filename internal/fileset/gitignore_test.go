@@ -0,0 +1,137 @@
+package fileset
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGitignore_Match(t *testing.T) {
+	dir := filepath.FromSlash("/repo")
+
+	tests := []struct {
+		name     string
+		lines    []string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{
+			name:     "basename pattern matches any level",
+			lines:    []string{"*.log"},
+			path:     "sub/dir/app.log",
+			expected: true,
+		},
+		{
+			name:     "basename pattern not matching",
+			lines:    []string{"*.log"},
+			path:     "sub/app.md",
+			expected: false,
+		},
+		{
+			name:     "anchored pattern matches from root",
+			lines:    []string{"/build"},
+			path:     "build",
+			isDir:    true,
+			expected: true,
+		},
+		{
+			name:     "anchored pattern does not match nested",
+			lines:    []string{"/build"},
+			path:     "sub/build",
+			isDir:    true,
+			expected: false,
+		},
+		{
+			name:     "path pattern",
+			lines:    []string{"docs/.vitepress/dist"},
+			path:     "docs/.vitepress/dist",
+			isDir:    true,
+			expected: true,
+		},
+		{
+			name:     "dir only pattern matches directory",
+			lines:    []string{"tmp/"},
+			path:     "a/tmp",
+			isDir:    true,
+			expected: true,
+		},
+		{
+			name:     "dir only pattern skips file",
+			lines:    []string{"tmp/"},
+			path:     "a/tmp",
+			isDir:    false,
+			expected: false,
+		},
+		{
+			name:     "negation re-includes file",
+			lines:    []string{"*.md", "!README.md"},
+			path:     "README.md",
+			expected: false,
+		},
+		{
+			name:     "last rule wins",
+			lines:    []string{"!README.md", "*.md"},
+			path:     "README.md",
+			expected: true,
+		},
+		{
+			name:     "double star",
+			lines:    []string{"drafts/**"},
+			path:     "drafts/2024/post.md",
+			expected: true,
+		},
+		{
+			name:     "comments and blank lines",
+			lines:    []string{"# *.md", "", "   "},
+			path:     "a.md",
+			expected: false,
+		},
+		{
+			name:     "escaped hash",
+			lines:    []string{`\#notes.md`},
+			path:     "#notes.md",
+			expected: true,
+		},
+		{
+			name:     "path outside gitignore dir",
+			lines:    []string{"*.md"},
+			path:     "../other/a.md",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ParseGitignore(dir, tt.lines)
+			got := g.Match(filepath.Join(dir, filepath.FromSlash(tt.path)), tt.isDir)
+			if got != tt.expected {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		rel      string
+		expected bool
+	}{
+		{"basename glob", []string{"*.md"}, "a/b/c.md", true},
+		{"basename glob not matching", []string{"*.md"}, "a/b/c.txt", false},
+		{"path glob", []string{"docs/**/*.md"}, "docs/a/b.md", true},
+		{"path glob other dir", []string{"docs/**/*.md"}, "src/a.md", false},
+		{"directory name", []string{"node_modules"}, "a/node_modules", true},
+		{"nested directory", []string{"**/.vitepress/dist"}, ".vitepress/dist", true},
+		{"empty patterns", nil, "a.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Match(tt.patterns, tt.rel); got != tt.expected {
+				t.Errorf("Match(%v, %q) = %v, want %v", tt.patterns, tt.rel, got, tt.expected)
+			}
+		})
+	}
+}
@@ -21,7 +21,7 @@ var (
 	imgRe          = regexp.MustCompile(`!\[([^\]]*)\]\([^)]+\)`)
 )
 
-// AnchorGenerator 生成 GitHub 风格的 anchor link
+// AnchorGenerator 生成 GitHub 风格的 anchor link (Slugger 的 SlugGitHub 实现)
 type AnchorGenerator struct {
 	counter map[string]int // 重复标题计数器
 }
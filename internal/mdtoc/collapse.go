@@ -0,0 +1,99 @@
+package mdtoc
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// generateGroups 生成按顶层条目分组的折叠列表
+// 带子条目的顶层条目渲染为 <details> 分组 (条目本身作为 <summary>)，
+// 连续的无子条目顶层条目保持为普通列表；有序列表的顶层编号在各部分之间连续
+func (g *Generator) generateGroups(headers []*Header, baseLevel int) string {
+	var blocks []string
+	counters := make(map[int]int)
+
+	var plain []*Header
+	flush := func() {
+		if len(plain) > 0 {
+			blocks = append(blocks, g.generateList(plain, baseLevel, counters))
+			plain = nil
+		}
+	}
+
+	for i := 0; i < len(headers); {
+		root := headers[i]
+		j := i + 1
+		for j < len(headers) && headers[j].Level > root.Level {
+			j++
+		}
+		children := headers[i+1 : j]
+		i = j
+
+		if len(children) == 0 {
+			plain = append(plain, root)
+			continue
+		}
+		flush()
+
+		counters[root.Level]++
+		for level := root.Level + 1; level <= 6; level++ {
+			counters[level] = 0
+		}
+		childLevel := 6
+		for _, h := range children {
+			childLevel = min(childLevel, h.Level)
+		}
+
+		blocks = append(blocks, "<details>\n<summary>"+g.summary(root, counters[root.Level])+"</summary>\n\n"+
+			g.generateList(children, childLevel, make(map[int]int))+"\n\n</details>")
+	}
+	flush()
+
+	return strings.Join(blocks, "\n\n")
+}
+
+// summary 生成分组的 <summary> 内容 (HTML)
+func (g *Generator) summary(h *Header, number int) string {
+	var sb strings.Builder
+	if g.options.Ordered {
+		n := number
+		if g.options.List.AllOnes {
+			n = 1
+		}
+		sb.WriteString(strconv.Itoa(n) + g.options.List.delimiter() + " ")
+	}
+
+	text := html.EscapeString(h.Text)
+	if g.options.ShowAnchor {
+		text = `<a href="#` + html.EscapeString(h.AnchorLink) + `">` + text + "</a>"
+	}
+	sb.WriteString(text)
+
+	if lines := g.lineRange(h); lines != "" {
+		lines = "<code>" + html.EscapeString(lines) + "</code>"
+		if g.options.Permalink != nil {
+			lines = `<a href="` + html.EscapeString(g.options.Permalink.URL(h.Line, h.EndLine)) + `">` + lines + "</a>"
+		}
+		sb.WriteString(" " + lines)
+	}
+	return sb.String()
+}
+
+// wrapDetails 将 TOC 包裹在 <details> 中
+// <summary> 后的空行使 GitHub 等渲染器继续按 Markdown 解析其中的列表
+func wrapDetails(toc, summary string) string {
+	return "<details>\n<summary>" + html.EscapeString(summary) + "</summary>\n\n" + toc + "\n\n</details>"
+}
+
+// unwrapDetails 去除 wrapDetails 添加的外层 <details>，不是折叠块时原样返回
+func unwrapDetails(toc string) string {
+	if !strings.HasPrefix(toc, "<details>\n<summary>") || !strings.HasSuffix(toc, "\n</details>") {
+		return toc
+	}
+	_, inner, ok := strings.Cut(toc, "</summary>\n")
+	if !ok {
+		return toc
+	}
+	return strings.TrimSpace(strings.TrimSuffix(inner, "</details>"))
+}
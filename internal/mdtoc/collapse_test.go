@@ -0,0 +1,104 @@
+package mdtoc
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGenerator_CollapseGroups(t *testing.T) {
+	headers := []*Header{
+		{Level: 2, Text: "A & B", AnchorLink: "a--b", Line: 3, EndLine: 6},
+		{Level: 3, Text: "A1", AnchorLink: "a1", Line: 5, EndLine: 6},
+		{Level: 2, Text: "C", AnchorLink: "c", Line: 7, EndLine: 7},
+		{Level: 2, Text: "D", AnchorLink: "d", Line: 8, EndLine: 9},
+		{Level: 3, Text: "D1", AnchorLink: "d1", Line: 9, EndLine: 9},
+	}
+
+	opts := Options{MinLevel: 2, MaxLevel: 3, Ordered: true, ShowAnchor: true, LineNumber: true, Collapse: CollapseGroups}
+	got := mustGenerate(t, NewGenerator(opts), headers)
+	expected := `<details>
+<summary>1. <a href="#a--b">A &amp; B</a> <code>:3+4</code></summary>
+
+1. [A1](#a1) ` + "`:5+2`" + `
+
+</details>
+
+2. [C](#c) ` + "`:7+1`" + `
+
+<details>
+<summary>3. <a href="#d">D</a> <code>:8+2</code></summary>
+
+1. [D1](#d1) ` + "`:9+1`" + `
+
+</details>`
+	if got != expected {
+		t.Errorf("Generate() =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestMarkerHandler_Collapsible(t *testing.T) {
+	h := NewMarkerHandler("")
+	h.SetCollapsible("Contents")
+
+	toc := "- [A](#a)\n- [B](#b)"
+	content := []byte("# Title\n<!--TOC-->\nOld\n<!--TOC-->\n## A")
+	got := string(h.InsertTOC(content, toc))
+	expected := "# Title\n<!--TOC-->\n\n<details>\n<summary>Contents</summary>\n\n- [A](#a)\n- [B](#b)\n\n</details>\n\n<!--TOC-->\n## A"
+	if got != expected {
+		t.Errorf("InsertTOC() =\n%s\nwant\n%s", got, expected)
+	}
+
+	if existing := h.ExtractExistingTOC([]byte(got)); existing != toc {
+		t.Errorf("ExtractExistingTOC() = %q, want %q", existing, toc)
+	}
+
+	// 折叠块增加 <details>、<summary>、两个空行和 </details> 共 5 行
+	if lines, plain := h.BlockLines(toc), CalcTOCBlockLines(toc); lines != plain+5 {
+		t.Errorf("BlockLines() = %d, want %d", lines, plain+5)
+	}
+
+	cleaned, blocks := h.CleanTOCBlocks([]byte(got))
+	if len(blocks) != 1 || string(cleaned) != "# Title\n## A" {
+		t.Errorf("CleanTOCBlocks() = %q (%d blocks)", cleaned, len(blocks))
+	}
+}
+
+func TestTOC_UpdateContent_Collapse(t *testing.T) {
+	content := "# One\n\n## A\n### A1\n## B\n\n# Two\n\ntext\n\n## C\n### C1\n"
+	rangeRe := regexp.MustCompile(`\[([^\]]+)\]\(#[^)]*\) ` + "`:(\\d+)\\+\\d+`" + `|<a href="#[^"]*">([^<]+)</a> <code>:(\d+)\+\d+</code>`)
+
+	for _, collapse := range []string{CollapseDetails, CollapseGroups} {
+		t.Run(collapse, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.LineNumber = true
+			opts.Collapse = collapse
+			toc := New(opts)
+
+			got, err := toc.UpdateContent([]byte(content))
+			if err != nil {
+				t.Fatalf("UpdateContent() error = %v", err)
+			}
+
+			// 折叠块计入行号偏移，每个条目的行号都指向对应标题
+			lines := strings.Split(string(got), "\n")
+			matches := rangeRe.FindAllStringSubmatch(string(got), -1)
+			if len(matches) != 5 {
+				t.Fatalf("found %d entries, want 5:\n%s", len(matches), got)
+			}
+			for _, m := range matches {
+				text, line := m[1]+m[3], m[2]+m[4]
+				n, _ := strconv.Atoi(line)
+				if lines[n-1] != "## "+text && lines[n-1] != "### "+text {
+					t.Errorf("entry %q points to line %d = %q", text, n, lines[n-1])
+				}
+			}
+
+			if again, _ := toc.UpdateContent(got); !bytes.Equal(again, got) {
+				t.Errorf("UpdateContent() not idempotent:\n%s\n---\n%s", got, again)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package mdtoc
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DocumentOptions 表示文档内的 TOC 设置 (来自 frontmatter 或 TOC 标记的内联选项)
+// 字段为 nil 表示文档未设置，使用传入 New 的 Options
+type DocumentOptions struct {
+	TOC      *bool   `yaml:"toc"`           // false 表示文档不生成 TOC
+	MinLevel *int    `yaml:"toc_min_level"` // 最小标题层级
+	MaxLevel *int    `yaml:"toc_max_level"` // 最大标题层级
+	Mode     *string `yaml:"toc_mode"`      // global | section
+	Ordered  *bool   `yaml:"toc_ordered"`   // 使用有序列表
+	Format   *string `yaml:"toc_format"`    // markdown | html | mermaid
+	Collapse *string `yaml:"toc_collapse"`  // none | details | groups
+}
+
+// ParseFrontmatter 解析 YAML frontmatter 中的 TOC 设置
+// 没有 frontmatter 时返回零值；与 TOC 无关的键会被忽略
+// frontmatter 属于整个文档，其他工具的键格式错误或无法解析时同样忽略，
+// 只有 toc_* 键的值无效时返回错误；toc 键可能由其他工具 (如 Hugo、VitePress) 使用，不是布尔值时忽略
+func ParseFrontmatter(content []byte) (DocumentOptions, error) {
+	var doc DocumentOptions
+
+	lines := bytes.Split(content, []byte("\n"))
+	end := FindFrontmatterEnd(lines)
+	if end < 0 {
+		return doc, nil
+	}
+
+	var raw map[string]yaml.Node
+	if err := yaml.Unmarshal(bytes.Join(lines[1:end], []byte("\n")), &raw); err != nil {
+		return doc, nil
+	}
+
+	if node, ok := raw["toc"]; ok {
+		var enabled bool
+		if node.Decode(&enabled) == nil {
+			doc.TOC = &enabled
+		}
+	}
+
+	fields := []struct {
+		key string
+		dst any
+	}{
+		{"toc_min_level", &doc.MinLevel},
+		{"toc_max_level", &doc.MaxLevel},
+		{"toc_mode", &doc.Mode},
+		{"toc_ordered", &doc.Ordered},
+		{"toc_format", &doc.Format},
+		{"toc_collapse", &doc.Collapse},
+	}
+	for _, f := range fields {
+		node, ok := raw[f.key]
+		if !ok {
+			continue
+		}
+		if err := node.Decode(f.dst); err != nil {
+			return doc, fmt.Errorf("frontmatter %s: %w", f.key, err)
+		}
+	}
+	return doc, nil
+}
+
+// IsZero 检查文档是否没有任何 TOC 设置
+func (d DocumentOptions) IsZero() bool {
+	return d.TOC == nil && d.MinLevel == nil && d.MaxLevel == nil && d.Mode == nil && d.Ordered == nil && d.Format == nil && d.Collapse == nil
+}
+
+// Disabled 检查文档是否通过 toc: false 关闭了 TOC
+func (d DocumentOptions) Disabled() bool {
+	return d.TOC != nil && !*d.TOC
+}
+
+// Apply 将文档设置合并到 opts 上并验证结果
+func (d DocumentOptions) Apply(opts Options) (Options, error) {
+	if d.MinLevel != nil {
+		opts.MinLevel = *d.MinLevel
+	}
+	if d.MaxLevel != nil {
+		opts.MaxLevel = *d.MaxLevel
+	}
+	if d.Ordered != nil {
+		opts.Ordered = *d.Ordered
+	}
+	if d.Mode != nil {
+		switch *d.Mode {
+		case "global":
+			opts.SectionTOC = false
+		case "section":
+			opts.SectionTOC = true
+		default:
+			return opts, fmt.Errorf("TOC 模式必须是 global 或 section: %q", *d.Mode)
+		}
+	}
+	if d.Format != nil {
+		switch *d.Format {
+		case FormatMarkdown, FormatHTML, FormatMermaid:
+			opts.Format = *d.Format
+		default:
+			return opts, fmt.Errorf("TOC 格式必须是 markdown、html 或 mermaid: %q", *d.Format)
+		}
+	}
+	if d.Collapse != nil {
+		switch *d.Collapse {
+		case CollapseNone, CollapseDetails, CollapseGroups:
+			opts.Collapse = *d.Collapse
+		default:
+			return opts, fmt.Errorf("TOC 折叠样式必须是 none、details 或 groups: %q", *d.Collapse)
+		}
+	}
+
+	if opts.MinLevel < 1 || opts.MinLevel > 6 {
+		return opts, fmt.Errorf("最小标题层级必须在 1-6 之间")
+	}
+	if opts.MaxLevel < 1 || opts.MaxLevel > 6 {
+		return opts, fmt.Errorf("最大标题层级必须在 1-6 之间")
+	}
+	if opts.MinLevel > opts.MaxLevel {
+		return opts, fmt.Errorf("最小标题层级不能大于最大标题层级")
+	}
+
+	return opts, nil
+}
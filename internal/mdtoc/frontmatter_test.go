@@ -0,0 +1,152 @@
+package mdtoc
+
+import (
+	"testing"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		isZero   bool
+		disabled bool
+		wantErr  bool
+	}{
+		{
+			name:    "no frontmatter",
+			content: "# Title\n## Section",
+			isZero:  true,
+		},
+		{
+			name:    "frontmatter without toc keys",
+			content: "---\ntitle: Test\nlayout: page\n---\n# Title",
+			isZero:  true,
+		},
+		{
+			name:     "toc disabled",
+			content:  "---\nlayout: home\ntoc: false\n---\n# Title",
+			disabled: true,
+		},
+		{
+			name:    "toc enabled explicitly",
+			content: "---\ntoc: true\n---\n# Title",
+		},
+		{
+			name:    "level settings",
+			content: "---\ntoc_min_level: 2\ntoc_max_level: 4\n---\n# Title",
+		},
+		{
+			name:    "unclosed frontmatter",
+			content: "---\ntoc: false\n# Title",
+			isZero:  true,
+		},
+		{
+			name:    "unrelated invalid key",
+			content: "---\ntitle: [unclosed\ntoc_max_level: 2\n---\n# Title",
+			isZero:  true,
+		},
+		{
+			name:    "unrelated key with wrong type",
+			content: "---\ndate: 2024-01-01\ntags: {a: 1}\ntoc_max_level: 2\n---\n# Title",
+		},
+		{
+			name:    "non-bool toc owned by another tool",
+			content: "---\ntoc:\n  depth: 2\ntoc_max_level: 2\n---\n# Title",
+		},
+		{
+			name:    "invalid type",
+			content: "---\ntoc_max_level: deep\n---\n# Title",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseFrontmatter([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFrontmatter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if doc.IsZero() != tt.isZero {
+				t.Errorf("IsZero() = %v, want %v", doc.IsZero(), tt.isZero)
+			}
+			if doc.Disabled() != tt.disabled {
+				t.Errorf("Disabled() = %v, want %v", doc.Disabled(), tt.disabled)
+			}
+		})
+	}
+}
+
+func TestDocumentOptions_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected Options
+		wantErr  bool
+	}{
+		{
+			name:     "no overrides",
+			content:  "---\ntitle: Test\n---\n",
+			expected: DefaultOptions(),
+		},
+		{
+			name:    "levels and ordered",
+			content: "---\ntoc_min_level: 2\ntoc_max_level: 4\ntoc_ordered: true\n---\n",
+			expected: Options{
+				MinLevel: 2, MaxLevel: 4, Ordered: true, SectionTOC: true, ShowAnchor: true,
+			},
+		},
+		{
+			name:    "global mode",
+			content: "---\ntoc_mode: global\n---\n",
+			expected: Options{
+				MinLevel: 1, MaxLevel: 3, SectionTOC: false, ShowAnchor: true,
+			},
+		},
+		{
+			name:    "invalid mode",
+			content: "---\ntoc_mode: sidebar\n---\n",
+			wantErr: true,
+		},
+		{
+			name:    "collapse",
+			content: "---\ntoc_collapse: groups\n---\n",
+			expected: Options{
+				MinLevel: 1, MaxLevel: 3, SectionTOC: true, ShowAnchor: true, Collapse: CollapseGroups,
+			},
+		},
+		{
+			name:    "invalid collapse",
+			content: "---\ntoc_collapse: folded\n---\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid level",
+			content: "---\ntoc_max_level: 7\n---\n",
+			wantErr: true,
+		},
+		{
+			name:    "min greater than max",
+			content: "---\ntoc_min_level: 4\ntoc_max_level: 2\n---\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseFrontmatter([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := doc.Apply(DefaultOptions())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.expected {
+				t.Errorf("Apply() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package mdtoc
 
 import (
+	"fmt"
+	"html"
 	"strconv"
 	"strings"
 )
@@ -18,19 +20,20 @@ func NewGenerator(opts Options) *Generator {
 }
 
 // Generate 从标题列表生成 TOC 字符串
-func (g *Generator) Generate(headers []*Header) string {
+// 只有自定义模板执行失败时返回错误
+func (g *Generator) Generate(headers []*Header) (string, error) {
 	if len(headers) == 0 {
-		return ""
+		return "", nil
 	}
-	return g.generateTOC(headers, g.options.MinLevel)
+	return g.generateTOC(headers, g.options.MinLevel, "")
 }
 
 // GenerateSection 为单个章节生成 TOC (只包含子标题)
 // 章节模式下，每个 H1 后面只生成该章节的子目录
 // 要求：章节内至少包含一个 H2 才会生成 TOC
-func (g *Generator) GenerateSection(section *Section) string {
+func (g *Generator) GenerateSection(section *Section) (string, error) {
 	if section == nil || len(section.SubHeaders) == 0 {
-		return ""
+		return "", nil
 	}
 
 	// 检查是否至少有一个 H2 (章节必须包含 H2 才生成 TOC)
@@ -42,7 +45,7 @@ func (g *Generator) GenerateSection(section *Section) string {
 		}
 	}
 	if !hasH2 {
-		return ""
+		return "", nil
 	}
 
 	// 筛选符合层级范围的子标题
@@ -54,7 +57,7 @@ func (g *Generator) GenerateSection(section *Section) string {
 	}
 
 	if len(filteredHeaders) == 0 {
-		return ""
+		return "", nil
 	}
 
 	// 找到最小层级作为基准 (章节模式下通常是 H2)
@@ -65,20 +68,45 @@ func (g *Generator) GenerateSection(section *Section) string {
 		}
 	}
 
-	return g.generateTOC(filteredHeaders, minLevel)
+	return g.generateTOC(filteredHeaders, minLevel, section.Title.Text)
 }
 
 // generateTOC 生成 TOC 字符串的内部实现
-// baseLevel 用于计算缩进的基准层级
-func (g *Generator) generateTOC(headers []*Header, baseLevel int) string {
+// baseLevel 用于计算缩进的基准层级，title 为章节标题 (全局模式为空)
+func (g *Generator) generateTOC(headers []*Header, baseLevel int, title string) (string, error) {
+	// 模板在加载时只用示例数据校验，实际标题 (如更深的层级) 仍可能执行失败，
+	// 失败时返回错误，不能退回默认格式写入文件
+	if g.options.Template != nil {
+		toc, err := g.options.Template.Render(headers, g.options.FilePath)
+		if err != nil {
+			return "", fmt.Errorf("TOC 模板: %w", err)
+		}
+		return toc, nil
+	}
+	switch g.options.Format {
+	case FormatHTML:
+		return g.generateHTML(headers), nil
+	case FormatMermaid:
+		return g.generateMermaid(headers, title), nil
+	}
+
+	if g.options.Collapse == CollapseGroups {
+		return g.generateGroups(headers, baseLevel), nil
+	}
+	return g.generateList(headers, baseLevel, make(map[int]int)), nil
+}
+
+// generateList 生成 Markdown 列表
+// orderedCounters 为各层级的有序列表编号，分组输出时顶层条目共用同一组编号
+func (g *Generator) generateList(headers []*Header, baseLevel int, orderedCounters map[int]int) string {
 	var sb strings.Builder
-	orderedCounters := make(map[int]int)
+	style := g.options.List
 
-	for i, h := range headers {
-		// 计算缩进 (相对于基准层级)
-		indent := (h.Level - baseLevel) * 2
-		indentStr := strings.Repeat(" ", indent)
+	// 按内容对齐时记录各层父条目的层级和内容起始列
+	type parent struct{ level, column int }
+	var parents []parent
 
+	for i, h := range headers {
 		// 生成列表标记
 		var marker string
 		if g.options.Ordered {
@@ -87,11 +115,30 @@ func (g *Generator) generateTOC(headers []*Header, baseLevel int) string {
 			for level := h.Level + 1; level <= 6; level++ {
 				orderedCounters[level] = 0
 			}
-			marker = strconv.Itoa(orderedCounters[h.Level]) + "."
+			n := orderedCounters[h.Level]
+			if style.AllOnes {
+				n = 1
+			}
+			marker = strconv.Itoa(n) + style.delimiter()
 		} else {
-			marker = "-"
+			marker = style.bullet()
 		}
 
+		// 计算缩进：按内容对齐时缩进到父条目内容的起始列，否则按相对于基准层级的层数
+		var indent int
+		if style.Align {
+			for len(parents) > 0 && parents[len(parents)-1].level >= h.Level {
+				parents = parents[:len(parents)-1]
+			}
+			if len(parents) > 0 {
+				indent = parents[len(parents)-1].column
+			}
+			parents = append(parents, parent{h.Level, indent + len(marker) + 1})
+		} else {
+			indent = (h.Level - baseLevel) * style.indent()
+		}
+		indentStr := strings.Repeat(" ", indent)
+
 		// 生成链接：ShowAnchor 控制是否包含 (#anchor) 部分
 		var link string
 		if g.options.ShowAnchor {
@@ -100,14 +147,14 @@ func (g *Generator) generateTOC(headers []*Header, baseLevel int) string {
 			link = "[" + h.Text + "]"
 		}
 
-		// 添加行号范围 (LLM 友好格式: :start+count)
-		if g.options.LineNumber && h.Line > 0 {
-			count := h.EndLine - h.Line + 1
-			if g.options.ShowPath && g.options.FilePath != "" {
-				link += " `" + g.options.FilePath + ":" + strconv.Itoa(h.Line) + "+" + strconv.Itoa(count) + "`"
-			} else {
-				link += " `:" + strconv.Itoa(h.Line) + "+" + strconv.Itoa(count) + "`"
+		// 添加行号范围 (默认为 LLM 友好格式: :start+count)
+		if lines := g.lineRange(h); lines != "" {
+			lines = "`" + lines + "`"
+			// 设置永久链接时行号范围链接到源码托管平台的对应行
+			if g.options.Permalink != nil {
+				lines = "[" + lines + "](" + g.options.Permalink.URL(h.Line, h.EndLine) + ")"
 			}
+			link += " " + lines
 		}
 
 		// 生成 TOC 行
@@ -122,3 +169,49 @@ func (g *Generator) generateTOC(headers []*Header, baseLevel int) string {
 
 	return sb.String()
 }
+
+// lineRange 返回标题的行号范围文本，未启用行号时为空
+func (g *Generator) lineRange(h *Header) string {
+	if !g.options.LineNumber || h.Line <= 0 {
+		return ""
+	}
+	var path string
+	if g.options.ShowPath {
+		path = g.options.FilePath
+	}
+	return formatLineRange(g.options.LineStyle, path, h.Line, h.EndLine)
+}
+
+// formatLineRange 按 LineStyle 格式化行号范围，path 非空时加在范围之前
+func formatLineRange(style, path string, start, end int) string {
+	s, e := strconv.Itoa(start), strconv.Itoa(end)
+	switch style {
+	case LineStyleColon:
+		if path != "" {
+			return path + ":" + s + ":" + e
+		}
+		return s + ":" + e
+	case LineStyleGitHub:
+		if path != "" {
+			return path + "#L" + s + "-L" + e
+		}
+		return "L" + s + "-L" + e
+	case LineStyleDash:
+		if path != "" {
+			return path + ":" + s + "-" + e
+		}
+		return s + "-" + e
+	case LineStyleStart:
+		return path + ":" + s
+	default:
+		return path + ":" + s + "+" + strconv.Itoa(end-start+1)
+	}
+}
+
+// SectionTitle 生成章节预览中的章节标题行
+func (g *Generator) SectionTitle(text string) string {
+	if g.options.Format == FormatHTML {
+		return "<h3>" + html.EscapeString(text) + "</h3>"
+	}
+	return "### " + text
+}
@@ -70,7 +70,7 @@ func TestGenerator_Generate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewGenerator(tt.opts)
-			got := g.Generate(tt.headers)
+			got := mustGenerate(t, g, tt.headers)
 			if got != tt.expected {
 				t.Errorf("Generate() =\n%s\nwant:\n%s", got, tt.expected)
 			}
@@ -78,6 +78,39 @@ func TestGenerator_Generate(t *testing.T) {
 	}
 }
 
+func TestGenerator_LineStyle(t *testing.T) {
+	headers := []*Header{
+		{Level: 2, Text: "Install", AnchorLink: "install", Line: 10, EndLine: 20},
+	}
+
+	tests := []struct {
+		style    string
+		expected string
+		withPath string
+	}{
+		{"", ":10+11", "a.md:10+11"},
+		{LineStylePlus, ":10+11", "a.md:10+11"},
+		{LineStyleColon, "10:20", "a.md:10:20"},
+		{LineStyleGitHub, "L10-L20", "a.md#L10-L20"},
+		{LineStyleDash, "10-20", "a.md:10-20"},
+		{LineStyleStart, ":10", "a.md:10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			opts := Options{MinLevel: 2, MaxLevel: 3, LineNumber: true, LineStyle: tt.style, FilePath: "a.md"}
+			if got := mustGenerate(t, NewGenerator(opts), headers); got != "- [Install] `"+tt.expected+"`" {
+				t.Errorf("Generate() = %q, want range %q", got, tt.expected)
+			}
+
+			opts.ShowPath = true
+			if got := mustGenerate(t, NewGenerator(opts), headers); got != "- [Install] `"+tt.withPath+"`" {
+				t.Errorf("Generate() with path = %q, want range %q", got, tt.withPath)
+			}
+		})
+	}
+}
+
 func TestGenerator_GenerateSection(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -158,7 +191,7 @@ func TestGenerator_GenerateSection(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewGenerator(tt.opts)
-			got := g.GenerateSection(tt.section)
+			got := mustGenerateSection(t, g, tt.section)
 			if got != tt.expected {
 				t.Errorf("GenerateSection() =\n%s\nwant:\n%s", got, tt.expected)
 			}
@@ -180,7 +213,7 @@ func TestGenerator_GenerateSection_RelativeIndent(t *testing.T) {
 	}
 
 	g := NewGenerator(Options{MinLevel: 1, MaxLevel: 6, ShowAnchor: true})
-	got := g.GenerateSection(section)
+	got := mustGenerateSection(t, g, section)
 
 	// H2 should be at root level (no indent), H3 indented by 2, H4 by 4
 	expected := `- [H2 First](#h2-first)
@@ -237,7 +270,7 @@ func TestGenerator_GenerateSection_RequiresH2(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewGenerator(Options{MinLevel: 2, MaxLevel: 6, ShowAnchor: true})
-			got := g.GenerateSection(tt.section)
+			got := mustGenerateSection(t, g, tt.section)
 			if got != tt.expected {
 				t.Errorf("GenerateSection() =\n%q\nwant:\n%q", got, tt.expected)
 			}
@@ -253,7 +286,7 @@ func TestGenerator_ChineseHeaders(t *testing.T) {
 	}
 
 	g := NewGenerator(DefaultOptions())
-	got := g.Generate(headers)
+	got := mustGenerate(t, g, headers)
 
 	// Verify Chinese text is preserved
 	if !strings.Contains(got, "第一章") {
@@ -263,3 +296,23 @@ func TestGenerator_ChineseHeaders(t *testing.T) {
 		t.Error("Generate() should preserve Chinese text with numbers")
 	}
 }
+
+// mustGenerate 生成全局 TOC，出错时终止测试
+func mustGenerate(t *testing.T, g *Generator, headers []*Header) string {
+	t.Helper()
+	toc, err := g.Generate(headers)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	return toc
+}
+
+// mustGenerateSection 生成章节 TOC，出错时终止测试
+func mustGenerateSection(t *testing.T, g *Generator, section *Section) string {
+	t.Helper()
+	toc, err := g.GenerateSection(section)
+	if err != nil {
+		t.Fatalf("GenerateSection() error = %v", err)
+	}
+	return toc
+}
@@ -0,0 +1,178 @@
+package mdtoc
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// generateHTML 生成 HTML <nav> 嵌套列表
+// 列表按标题层级嵌套，LineNumber 启用时行号范围写入 data-line / data-end-line 属性，
+// 设置永久链接时写入 data-permalink
+func (g *Generator) generateHTML(headers []*Header) string {
+	var sb strings.Builder
+	sb.WriteString(`<nav class="toc">` + "\n")
+	g.writeHTMLList(&sb, BuildTree(headers), 1)
+	sb.WriteString("</nav>")
+	return sb.String()
+}
+
+// writeHTMLList 递归写入一层列表，depth 控制缩进
+func (g *Generator) writeHTMLList(sb *strings.Builder, nodes []*OutlineNode, depth int) {
+	tag := "ul"
+	if g.options.Ordered {
+		tag = "ol"
+	}
+	indent := strings.Repeat("  ", depth)
+
+	sb.WriteString(indent + "<" + tag + ">\n")
+	for _, n := range nodes {
+		sb.WriteString(indent + "  <li")
+		if g.options.HTMLClass != "" {
+			sb.WriteString(` class="` + html.EscapeString(g.options.HTMLClass+strconv.Itoa(n.Level)) + `"`)
+		}
+		sb.WriteString(`><a href="#` + html.EscapeString(n.Anchor) + `"`)
+		if g.options.LineNumber && n.Line > 0 {
+			if g.options.ShowPath && g.options.FilePath != "" {
+				sb.WriteString(` data-path="` + html.EscapeString(g.options.FilePath) + `"`)
+			}
+			sb.WriteString(` data-line="` + strconv.Itoa(n.Line) + `" data-end-line="` + strconv.Itoa(n.EndLine) + `"`)
+			if g.options.Permalink != nil {
+				sb.WriteString(` data-permalink="` + html.EscapeString(g.options.Permalink.URL(n.Line, n.EndLine)) + `"`)
+			}
+		}
+		sb.WriteString(">" + html.EscapeString(n.Text) + "</a>")
+
+		if len(n.Children) > 0 {
+			sb.WriteString("\n")
+			g.writeHTMLList(sb, n.Children, depth+2)
+			sb.WriteString(indent + "  ")
+		}
+		sb.WriteString("</li>\n")
+	}
+	sb.WriteString(indent + "</" + tag + ">\n")
+}
+
+var (
+	// htmlHeadingRe 匹配 <h1>-<h6> 标签 (不支持嵌套标题，RE2 不支持反向引用，闭合标签层级单独校验)
+	htmlHeadingRe = regexp.MustCompile(`(?is)<h([1-6])(\s[^>]*)?>(.*?)</h([1-6])\s*>`)
+	// htmlIDRe 提取标签的 id 属性
+	htmlIDRe = regexp.MustCompile(`(?i)(?:^|\s)id\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	// htmlSkipRe 匹配不包含有效标题的区域：注释、脚本、样式和预格式化文本
+	htmlSkipRe = regexp.MustCompile(`(?is)<!--.*?-->|<script\b.*?</script\s*>|<style\b.*?</style\s*>|<pre\b.*?</pre\s*>`)
+)
+
+// htmlHeading 记录 HTML 标题及其在原文中的位置
+type htmlHeading struct {
+	header  *Header
+	hasID   bool // 标签带有非空的 id 属性
+	idStart int  // 写入生成 id 时替换的范围：空的 id 属性本身，没有 id 属性时为紧跟 <hN 之后的空范围
+	idEnd   int
+}
+
+// parseHTMLHeaders 从 HTML 文档中提取 <h1>-<h6> 标题
+// 标题带 id 属性时直接作为锚点，否则按标题文本生成 (不与文档中的 id 重复)
+func (p *Parser) parseHTMLHeaders(content []byte, anchors Slugger, filterLevel bool) []*Header {
+	var headers []*Header
+	for _, h := range scanHTMLHeadings(content, anchors) {
+		headers = append(headers, h.header)
+	}
+	return p.finishHeaders(headers, countLines(content), filterLevel)
+}
+
+// addHTMLHeadingIDs 为层级范围内没有 id 属性 (或 id 为空) 的标题写入生成的 id
+// 生成的锚点只存在于 TOC 中，不写回标题时 <nav> 中的链接无法跳转；
+// 补充的 id 在下次解析时作为显式 id，结果保持稳定
+func (p *Parser) addHTMLHeadingIDs(content []byte) ([]byte, error) {
+	anchors, err := NewSlugger(p.options.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	last := 0
+	for _, h := range scanHTMLHeadings(content, anchors) {
+		if h.hasID || h.header.Level < p.options.MinLevel || h.header.Level > p.options.MaxLevel {
+			continue
+		}
+		out = append(out, content[last:h.idStart]...)
+		out = append(out, ` id="`+html.EscapeString(h.header.AnchorLink)+`"`...)
+		last = h.idEnd
+	}
+	if out == nil {
+		return content, nil
+	}
+	return append(out, content[last:]...), nil
+}
+
+// scanHTMLHeadings 按文档顺序提取 <h1>-<h6> 标题并确定锚点
+func scanHTMLHeadings(content []byte, anchors Slugger) []htmlHeading {
+	// 将跳过区域替换为等长空白 (保留换行)，保证匹配位置与原文行号一致
+	masked := htmlSkipRe.ReplaceAllFunc(content, func(b []byte) []byte {
+		out := make([]byte, len(b))
+		for i, c := range b {
+			if c == '\n' {
+				out[i] = '\n'
+			} else {
+				out[i] = ' '
+			}
+		}
+		return out
+	})
+
+	lineMap := buildLineMap(masked)
+
+	var headings []htmlHeading
+	explicit := make(map[string]bool)
+	for _, m := range htmlHeadingRe.FindAllSubmatchIndex(masked, -1) {
+		level, _ := strconv.Atoi(string(masked[m[2]:m[3]]))
+		if closing, _ := strconv.Atoi(string(masked[m[8]:m[9]])); closing != level {
+			continue
+		}
+
+		text := htmlTagRe.ReplaceAllString(string(masked[m[6]:m[7]]), "")
+		text = strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+
+		// 空的 id 属性视为没有 id，写入生成的 id 时替换该属性
+		var id string
+		idStart, idEnd := m[3], m[3]
+		if m[4] >= 0 {
+			attrs := string(masked[m[4]:m[5]])
+			if loc := htmlIDRe.FindStringSubmatchIndex(attrs); loc != nil {
+				for g := 2; g < len(loc); g += 2 {
+					if loc[g] >= 0 {
+						id = html.UnescapeString(attrs[loc[g]:loc[g+1]])
+					}
+				}
+				if id == "" {
+					// 匹配以属性前的空白开头，替换后由写入的 " id=..." 补回
+					idStart, idEnd = m[4]+loc[0], m[4]+loc[1]
+				}
+			}
+		}
+		if id != "" {
+			explicit[id] = true
+		}
+
+		headings = append(headings, htmlHeading{
+			header: &Header{
+				Level:      level,
+				Text:       text,
+				AnchorLink: id,
+				Line:       lineMap[m[0]],
+			},
+			hasID:   id != "",
+			idStart: idStart,
+			idEnd:   idEnd,
+		})
+	}
+
+	for _, h := range headings {
+		if h.header.AnchorLink == "" {
+			h.header.AnchorLink = generateAnchor(anchors, h.header.Text, explicit)
+		}
+	}
+
+	return headings
+}
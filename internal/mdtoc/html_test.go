@@ -0,0 +1,141 @@
+package mdtoc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerator_HTML(t *testing.T) {
+	headers := []*Header{
+		{Level: 2, Text: "Install & Setup", AnchorLink: "install--setup", Line: 3, EndLine: 8},
+		{Level: 3, Text: "Linux", AnchorLink: "linux", Line: 5, EndLine: 8},
+		{Level: 2, Text: "Usage", AnchorLink: "usage", Line: 9, EndLine: 12},
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{
+			name: "nested list with line ranges",
+			opts: Options{MinLevel: 2, MaxLevel: 3, LineNumber: true, Format: FormatHTML},
+			expected: `<nav class="toc">
+  <ul>
+    <li><a href="#install--setup" data-line="3" data-end-line="8">Install &amp; Setup</a>
+      <ul>
+        <li><a href="#linux" data-line="5" data-end-line="8">Linux</a></li>
+      </ul>
+    </li>
+    <li><a href="#usage" data-line="9" data-end-line="12">Usage</a></li>
+  </ul>
+</nav>`,
+		},
+		{
+			name: "ordered with classes and path",
+			opts: Options{MinLevel: 2, MaxLevel: 2, Ordered: true, LineNumber: true, ShowPath: true, FilePath: "a.md", Format: FormatHTML, HTMLClass: "toc-h"},
+			expected: `<nav class="toc">
+  <ol>
+    <li class="toc-h2"><a href="#install--setup" data-path="a.md" data-line="3" data-end-line="8">Install &amp; Setup</a></li>
+    <li class="toc-h2"><a href="#usage" data-path="a.md" data-line="9" data-end-line="12">Usage</a></li>
+  </ol>
+</nav>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filtered []*Header
+			for _, h := range headers {
+				if h.Level <= tt.opts.MaxLevel {
+					filtered = append(filtered, h)
+				}
+			}
+			got := mustGenerate(t, NewGenerator(tt.opts), filtered)
+			if got != tt.expected {
+				t.Errorf("Generate() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParser_HTMLInput(t *testing.T) {
+	content := `<html>
+<h1 id="top">Guide &amp; Intro</h1>
+<!-- <h2>Commented</h2> -->
+<pre><h2>Code</h2></pre>
+<h2>Install <code>pkg</code></h2>
+<h3 class="x" id='linux'>Linux</h3>
+<h2>
+  Usage
+</h2>
+<h2>Broken</h3>
+</html>`
+
+	p := NewParser(Options{MinLevel: 1, MaxLevel: 6, HTMLInput: true})
+	headers, err := p.Parse([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*Header{
+		{Level: 1, Text: "Guide & Intro", AnchorLink: "top", Line: 2, EndLine: 11},
+		{Level: 2, Text: "Install pkg", AnchorLink: "install-pkg", Line: 5, EndLine: 6},
+		{Level: 3, Text: "Linux", AnchorLink: "linux", Line: 6, EndLine: 6},
+		{Level: 2, Text: "Usage", AnchorLink: "usage", Line: 7, EndLine: 11},
+	}
+	if len(headers) != len(expected) {
+		t.Fatalf("Parse() got %d headers, want %d: %+v", len(headers), len(expected), headers)
+	}
+	for i, want := range expected {
+		if *headers[i] != *want {
+			t.Errorf("headers[%d] = %+v, want %+v", i, *headers[i], *want)
+		}
+	}
+}
+
+func TestTOC_UpdateContent_HTMLInput(t *testing.T) {
+	toc := New(Options{MinLevel: 2, MaxLevel: 3, ShowAnchor: true, SectionTOC: true, Format: FormatHTML, HTMLInput: true})
+
+	// 没有标记时不自动插入
+	noMarker := "<h1>Title</h1>\n<h2>A</h2>\n"
+	got, err := toc.UpdateContent([]byte(noMarker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != noMarker {
+		t.Errorf("UpdateContent() without marker = %q, want unchanged", got)
+	}
+
+	// 有标记时以全局模式在标记处插入 <nav>，没有 id 的标题写入生成的 id
+	withMarker := "<h1>Title</h1>\n<!--TOC-->\n<h2 id=\"intro\">Intro</h2>\n<h2>A</h2>\n<h3 class=\"x\">A</h3>\n<h2 class=\"y\" id=\"\">Empty</h2>\n"
+	got, err = toc.UpdateContent([]byte(withMarker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<li><a href="#intro">Intro</a></li>`,
+		`<a href="#a">A</a>`,
+		`<li><a href="#a-1">A</a></li>`,
+		`<li><a href="#empty">Empty</a></li>`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("UpdateContent() should contain %s, got:\n%s", want, got)
+		}
+	}
+	if !strings.HasPrefix(string(got), "<h1>Title</h1>\n<!--TOC-->\n") {
+		t.Errorf("UpdateContent() should not add id to headings outside level range, got:\n%s", got)
+	}
+	if !strings.HasSuffix(string(got), "<!--TOC-->\n<h2 id=\"intro\">Intro</h2>\n<h2 id=\"a\">A</h2>\n<h3 id=\"a-1\" class=\"x\">A</h3>\n<h2 class=\"y\" id=\"empty\">Empty</h2>\n") {
+		t.Errorf("UpdateContent() should add ids matching nav links, got:\n%s", got)
+	}
+
+	// 再次更新结果不变
+	again, err := toc.UpdateContent(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(got) {
+		t.Errorf("UpdateContent() not idempotent:\n%s\n---\n%s", got, again)
+	}
+}
@@ -0,0 +1,132 @@
+package mdtoc
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// HeadingIDSlugger 由 goldmark 在解析时为标题分配 id 的锚点规则
+// 解析器直接使用 ast.Heading 的 id 属性 (由标题的 Markdown 原文生成)，
+// Generate 只用于 HTML 文档等不经过 goldmark 解析的输入
+type HeadingIDSlugger interface {
+	Slugger
+	IDs() parser.IDs
+}
+
+// Hugo 的标题 id 类型 (markup.goldmark.parser.autoHeadingIDType)
+const (
+	hugoIDGitHub      = "github"       // 默认：保留 Unicode 字母和数字
+	hugoIDGitHubASCII = "github-ascii" // 移除变音符号后只保留 ASCII 字符
+	hugoIDBlackfriday = "blackfriday"  // 与 Hugo 0.60 之前的 Blackfriday 渲染器一致
+)
+
+// HugoSlugger 生成与 Hugo (goldmark 渲染器) 一致的标题 id
+type HugoSlugger struct {
+	idType string              // Hugo 的标题 id 类型
+	used   map[string]struct{} // 已使用的 id
+}
+
+// NewHugoSlugger 创建 Hugo 风格的锚点生成器，idType 为 github、github-ascii 或 blackfriday
+func NewHugoSlugger(idType string) *HugoSlugger {
+	return &HugoSlugger{idType: idType, used: make(map[string]struct{})}
+}
+
+// Generate 生成 anchor link
+// 规则 (参考 Hugo markup/goldmark/autoid.go):
+//   - github: 去除首尾空白，保留 Unicode 字母、数字和 _ 并转小写，空格和 - 转为 -，其余字符移除 (不合并连字符)
+//   - github-ascii: 先移除变音符号 (é -> e)，再按 github 规则处理并丢弃非 ASCII 字符
+//   - blackfriday: 保留字母和数字，其余连续字符替换为一个 - (不出现在首尾)
+//
+// 结果为空时使用 heading；与已使用的 id 重复时依次尝试 -1, -2, ...
+func (g *HugoSlugger) Generate(text string) string {
+	id := g.sanitize([]byte(text))
+	if id == "" {
+		id = "heading"
+	}
+
+	unique := id
+	for i := 1; g.exists(unique); i++ {
+		unique = id + "-" + strconv.Itoa(i)
+	}
+	g.used[unique] = struct{}{}
+	return unique
+}
+
+// IDs 返回供 goldmark 解析时使用的 id 生成器，与 Generate 共享已使用的 id
+func (g *HugoSlugger) IDs() parser.IDs {
+	return hugoIDs{g}
+}
+
+// exists 判断 id 是否已被使用
+func (g *HugoSlugger) exists(id string) bool {
+	_, ok := g.used[id]
+	return ok
+}
+
+// sanitize 按 id 类型转换标题文本
+func (g *HugoSlugger) sanitize(b []byte) string {
+	if g.idType == hugoIDBlackfriday {
+		return blackfridayAnchor(string(b))
+	}
+
+	asciiOnly := g.idType == hugoIDGitHubASCII
+	if asciiOnly {
+		b, _, _ = transform.Bytes(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), b)
+	}
+
+	var sb strings.Builder
+	for b = bytes.TrimSpace(b); len(b) > 0; {
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+		switch {
+		case asciiOnly && size != 1:
+		case r == '-' || r == ' ':
+			sb.WriteByte('-')
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// blackfridayAnchor 与 blackfriday.SanitizedAnchorName 一致
+func blackfridayAnchor(text string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			dash = true
+			continue
+		}
+		if dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+		}
+		dash = false
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// hugoIDs 将 HugoSlugger 适配为 goldmark 的 parser.IDs
+type hugoIDs struct {
+	g *HugoSlugger
+}
+
+// Generate 为 goldmark 节点生成 id，value 为标题最后一行的 Markdown 原文
+func (ids hugoIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	return []byte(ids.g.Generate(string(value)))
+}
+
+// Put 记录文档中显式指定的 id
+func (ids hugoIDs) Put(value []byte) {
+	ids.g.used[string(value)] = struct{}{}
+}
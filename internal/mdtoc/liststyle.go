@@ -0,0 +1,114 @@
+package mdtoc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ListStyle Markdown 列表格式
+// 零值等同于 default 配置：- 符号、每层缩进 2 个空格、编号递增的 1. 2. 3.
+type ListStyle struct {
+	Bullet    string // 无序列表符号: -、* 或 +，空值为 -
+	Indent    int    // 每层缩进的空格数，0 表示 2
+	Align     bool   // 子列表缩进对齐到父条目内容 (列表符号宽度 + 1)，设置后忽略 Indent
+	Delimiter string // 有序列表编号后的符号: . 或 )，空值为 .
+	AllOnes   bool   // 有序列表所有条目都使用 1 编号
+}
+
+// ListProfiles 预置的列表格式
+// prettier 按内容对齐缩进 (有序列表为 3 个空格)，格式化工具不会改写生成的 TOC
+var ListProfiles = map[string]ListStyle{
+	"default":  {Bullet: "-", Indent: 2, Delimiter: "."},
+	"prettier": {Bullet: "-", Align: true, Delimiter: "."},
+}
+
+// ListProfileAliases 列表格式配置的别名
+// markdownlint 的默认规则 (MD004 consistent、MD007 indent 2、MD029 one_or_ordered) 接受 prettier 的输出：
+// 无序子列表缩进 2 个空格即对齐到 "- " 之后，有序子列表必须对齐到编号之后才能构成嵌套
+var ListProfileAliases = map[string]string{
+	"markdownlint": "prettier",
+}
+
+// ParseListStyle 解析列表格式描述
+// 格式为逗号分隔的配置名和选项，后面的选项覆盖前面的值，例如 "prettier,bullet=*"：
+//   - default / prettier：预置配置，markdownlint 为 prettier 的别名
+//   - bullet=-|*|+：无序列表符号
+//   - indent=N：每层缩进空格数 (2-4)
+//   - align：按内容对齐缩进
+//   - delimiter=.|)：有序列表编号后的符号
+//   - numbering=increment|one：编号递增或全部为 1
+func ParseListStyle(s string) (ListStyle, error) {
+	var style ListStyle
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if alias, ok := ListProfileAliases[field]; ok {
+			field = alias
+		}
+		if profile, ok := ListProfiles[field]; ok {
+			style = profile
+			continue
+		}
+
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "bullet":
+			if value != "-" && value != "*" && value != "+" {
+				return style, fmt.Errorf("列表符号必须是 -、* 或 +: %q", value)
+			}
+			style.Bullet = value
+		case "indent":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 2 || n > 4 {
+				return style, fmt.Errorf("列表缩进必须在 2-4 之间: %q", value)
+			}
+			style.Indent, style.Align = n, false
+		case "align":
+			style.Align = true
+		case "delimiter":
+			if value != "." && value != ")" {
+				return style, fmt.Errorf("有序列表符号必须是 . 或 ): %q", value)
+			}
+			style.Delimiter = value
+		case "numbering":
+			switch value {
+			case "increment":
+				style.AllOnes = false
+			case "one":
+				style.AllOnes = true
+			default:
+				return style, fmt.Errorf("有序列表编号必须是 increment 或 one: %q", value)
+			}
+		default:
+			return style, fmt.Errorf("未知的列表格式选项: %q", field)
+		}
+	}
+	return style, nil
+}
+
+// bullet 返回无序列表符号
+func (s ListStyle) bullet() string {
+	if s.Bullet == "" {
+		return "-"
+	}
+	return s.Bullet
+}
+
+// indent 返回每层缩进的空格数
+func (s ListStyle) indent() int {
+	if s.Indent <= 0 {
+		return 2
+	}
+	return s.Indent
+}
+
+// delimiter 返回有序列表编号后的符号
+func (s ListStyle) delimiter() string {
+	if s.Delimiter == "" {
+		return "."
+	}
+	return s.Delimiter
+}
@@ -0,0 +1,138 @@
+package mdtoc
+
+import (
+	"testing"
+)
+
+func TestParseListStyle(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ListStyle
+		wantErr  bool
+	}{
+		{input: "", expected: ListStyle{}},
+		{input: "prettier", expected: ListProfiles["prettier"]},
+		{input: "markdownlint", expected: ListProfiles["prettier"]},
+		{input: "markdownlint,bullet=*", expected: ListStyle{Bullet: "*", Align: true, Delimiter: "."}},
+		{input: "prettier, bullet=*", expected: ListStyle{Bullet: "*", Align: true, Delimiter: "."}},
+		{input: "prettier,indent=4", expected: ListStyle{Bullet: "-", Indent: 4, Delimiter: "."}},
+		{input: "delimiter=),numbering=one", expected: ListStyle{Delimiter: ")", AllOnes: true}},
+		{input: "bullet=x", wantErr: true},
+		{input: "indent=1", wantErr: true},
+		{input: "delimiter=:", wantErr: true},
+		{input: "numbering=roman", wantErr: true},
+		{input: "compact", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseListStyle(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseListStyle(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.expected {
+				t.Errorf("ParseListStyle(%q) = %+v, want %+v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerator_ListStyle(t *testing.T) {
+	headers := []*Header{
+		{Level: 2, Text: "A"},
+		{Level: 3, Text: "A1"},
+		{Level: 5, Text: "A1a"}, // 跳级标题
+		{Level: 2, Text: "B"},
+	}
+
+	tests := []struct {
+		name     string
+		ordered  bool
+		style    ListStyle
+		expected string
+	}{
+		{
+			name:     "default",
+			style:    ListStyle{},
+			expected: "- [A]\n  - [A1]\n      - [A1a]\n- [B]",
+		},
+		{
+			name:     "bullet and indent",
+			style:    ListStyle{Bullet: "*", Indent: 4},
+			expected: "* [A]\n    * [A1]\n            * [A1a]\n* [B]",
+		},
+		{
+			name:     "ordered aligned",
+			ordered:  true,
+			style:    ListProfiles["prettier"],
+			expected: "1. [A]\n   1. [A1]\n      1. [A1a]\n2. [B]",
+		},
+		{
+			name:     "ordered all ones with parenthesis",
+			ordered:  true,
+			style:    ListStyle{Delimiter: ")", AllOnes: true, Align: true},
+			expected: "1) [A]\n   1) [A1]\n      1) [A1a]\n1) [B]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{MinLevel: 2, MaxLevel: 5, Ordered: tt.ordered, List: tt.style}
+			if got := mustGenerate(t, NewGenerator(opts), headers); got != tt.expected {
+				t.Errorf("Generate() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerator_ListStyle_WideMarker(t *testing.T) {
+	var headers []*Header
+	for range 10 {
+		headers = append(headers, &Header{Level: 2, Text: "S"})
+	}
+	headers = append(headers, &Header{Level: 3, Text: "C"})
+
+	got := mustGenerate(t, NewGenerator(Options{MinLevel: 2, MaxLevel: 3, Ordered: true, List: ListProfiles["prettier"]}), headers)
+	// 子条目对齐到 "10. " 之后的内容列
+	want := "10. [S]\n    1. [C]"
+	if got[len(got)-len(want):] != want {
+		t.Errorf("Generate() tail =\n%s\nwant\n%s", got[len(got)-len(want):], want)
+	}
+}
+
+// TestListProfiles 测试预置配置的差异：default 固定缩进 2 个空格，
+// prettier (及其别名 markdownlint) 对齐到父条目内容，只影响有序列表
+func TestListProfiles(t *testing.T) {
+	headers := []*Header{
+		{Level: 2, Text: "A"},
+		{Level: 3, Text: "A1"},
+		{Level: 2, Text: "B"},
+	}
+
+	tests := []struct {
+		profile   string
+		unordered string
+		ordered   string
+	}{
+		{"default", "- [A]\n  - [A1]\n- [B]", "1. [A]\n  1. [A1]\n2. [B]"},
+		{"prettier", "- [A]\n  - [A1]\n- [B]", "1. [A]\n   1. [A1]\n2. [B]"},
+		{"markdownlint", "- [A]\n  - [A1]\n- [B]", "1. [A]\n   1. [A1]\n2. [B]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			style, err := ParseListStyle(tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := Options{MinLevel: 2, MaxLevel: 3, List: style}
+			if got := mustGenerate(t, NewGenerator(opts), headers); got != tt.unordered {
+				t.Errorf("unordered =\n%s\nwant\n%s", got, tt.unordered)
+			}
+			opts.Ordered = true
+			if got := mustGenerate(t, NewGenerator(opts), headers); got != tt.ordered {
+				t.Errorf("ordered =\n%s\nwant\n%s", got, tt.ordered)
+			}
+		})
+	}
+}
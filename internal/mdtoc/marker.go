@@ -2,6 +2,8 @@ package mdtoc
 
 import (
 	"bytes"
+	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -33,7 +35,8 @@ func FindFrontmatterEnd(lines [][]byte) int {
 
 // MarkerHandler 处理 <!--TOC--> 标记
 type MarkerHandler struct {
-	marker string
+	marker  string
+	summary string // 非空时插入的 TOC 包裹在 <details> 中，作为 <summary> 文本
 }
 
 // NewMarkerHandler 创建新的标记处理器
@@ -44,11 +47,114 @@ func NewMarkerHandler(marker string) *MarkerHandler {
 	return &MarkerHandler{marker: marker}
 }
 
+// SetCollapsible 设置插入的 TOC 折叠在 <details> 中，summary 为空时取消折叠
+// 折叠块位于两个标记之间，删除和更新 TOC 时随标记一起处理
+func (h *MarkerHandler) SetCollapsible(summary string) {
+	h.summary = summary
+}
+
+// wrap 返回写入两个标记之间的 TOC 内容
+func (h *MarkerHandler) wrap(toc string) string {
+	if h.summary == "" {
+		return toc
+	}
+	return wrapDetails(toc, h.summary)
+}
+
+// IsMarker 检查一行是否为 TOC 标记 (包括带内联选项的标记)
+func (h *MarkerHandler) IsMarker(line []byte) bool {
+	_, ok := h.ParseMarkerLine(line)
+	return ok
+}
+
+// ParseMarkerLine 解析标记行，返回内联选项原文
+// 例如 "<!--TOC max-level=2 ordered-->" 返回 "max-level=2 ordered", true
+// 不是 TOC 标记时返回 "", false；选项无法解析的注释 (如 "<!--TOC below is generated-->")
+// 视为普通注释，避免与真正的标记配对后误删其间的内容
+func (h *MarkerHandler) ParseMarkerLine(line []byte) (string, bool) {
+	trimmed := string(bytes.TrimSpace(line))
+	if trimmed == h.marker {
+		return "", true
+	}
+
+	// 只有 HTML 注释形式的标记支持内联选项
+	prefix, ok := strings.CutSuffix(h.marker, "-->")
+	if !ok || len(trimmed) < len(prefix)+len("-->") {
+		return "", false
+	}
+	if !strings.HasPrefix(trimmed, prefix) || !strings.HasSuffix(trimmed, "-->") {
+		return "", false
+	}
+
+	// 标记名与选项之间必须有空白，避免匹配 <!--TOCXXX-->
+	rest := trimmed[len(prefix) : len(trimmed)-len("-->")]
+	if rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+	options := strings.TrimSpace(rest)
+	if _, err := ParseMarkerOptions(options); err != nil {
+		return "", false
+	}
+	return options, true
+}
+
+// ParseMarkerOptions 解析标记中的内联选项
+// 支持的选项：
+//   - min-level=N / max-level=N：标题层级范围
+//   - ordered / unordered / ordered=true|false：列表类型
+//   - global / section / mode=global|section：TOC 模式
+//   - format=markdown|html|mermaid：TOC 渲染格式
+func ParseMarkerOptions(s string) (DocumentOptions, error) {
+	var doc DocumentOptions
+
+	for _, field := range strings.Fields(s) {
+		key, value, hasValue := strings.Cut(field, "=")
+
+		switch key {
+		case "min-level", "max-level":
+			n, err := strconv.Atoi(value)
+			if !hasValue || err != nil {
+				return doc, fmt.Errorf("%s 需要整数值: %q", key, field)
+			}
+			if key == "min-level" {
+				doc.MinLevel = &n
+			} else {
+				doc.MaxLevel = &n
+			}
+		case "ordered", "unordered":
+			ordered := key == "ordered"
+			if hasValue {
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return doc, fmt.Errorf("%s 需要布尔值: %q", key, field)
+				}
+				ordered = ordered == b
+			}
+			doc.Ordered = &ordered
+		case "global", "section":
+			if hasValue {
+				return doc, fmt.Errorf("%s 不接受参数: %q", key, field)
+			}
+			mode := key
+			doc.Mode = &mode
+		case "mode":
+			mode := value
+			doc.Mode = &mode
+		case "format":
+			format := value
+			doc.Format = &format
+		default:
+			return doc, fmt.Errorf("未知的 TOC 标记选项: %q", field)
+		}
+	}
+
+	return doc, nil
+}
+
 // FindMarkers 查找 TOC 标记位置
 // 注意：会跳过 YAML frontmatter 内部的标记
 func (h *MarkerHandler) FindMarkers(content []byte) *TOCMarker {
 	lines := bytes.Split(content, []byte("\n"))
-	markerBytes := []byte(h.marker)
 
 	// 找到 frontmatter 结束位置
 	frontmatterEnd := FindFrontmatterEnd(lines)
@@ -59,8 +165,7 @@ func (h *MarkerHandler) FindMarkers(content []byte) *TOCMarker {
 
 	var positions []int
 	for i := startLine; i < len(lines); i++ {
-		trimmed := bytes.TrimSpace(lines[i])
-		if bytes.Equal(trimmed, markerBytes) {
+		if h.IsMarker(lines[i]) {
 			positions = append(positions, i)
 		}
 	}
@@ -73,6 +178,7 @@ func (h *MarkerHandler) FindMarkers(content []byte) *TOCMarker {
 
 	if len(positions) >= 1 {
 		result.StartLine = positions[0]
+		result.Options, _ = h.ParseMarkerLine(lines[positions[0]])
 		result.Found = true
 	}
 	if len(positions) >= 2 {
@@ -101,32 +207,23 @@ func (h *MarkerHandler) InsertTOC(content []byte, toc string) []byte {
 			if i == markers.StartLine {
 				// 添加空行 + TOC + 空行 + 结束标记
 				result = append(result, []byte(""))
-				result = append(result, []byte(toc))
+				result = append(result, []byte(h.wrap(toc)))
 				result = append(result, []byte(""))
 				result = append(result, []byte(h.marker))
 			}
 		}
 	} else {
 		// 两个标记：替换两个标记之间的内容
-		skipNextEmpty := false
+		// 结束标记之后的内容原样保留 (TOC 块不会额外添加空行，重复执行结果稳定)
 		for i, line := range lines {
 			if i < markers.StartLine {
 				result = append(result, line)
 			} else if i == markers.StartLine {
 				result = append(result, line)
 				result = append(result, []byte(""))
-				result = append(result, []byte(toc))
+				result = append(result, []byte(h.wrap(toc)))
 				result = append(result, []byte(""))
-			} else if i == markers.EndLine {
-				result = append(result, line)
-				skipNextEmpty = true // 标记：跳过结束标记后的第一个空行
-			} else if i > markers.EndLine {
-				// 跳过结束标记后紧跟的空行，避免空行累积
-				if skipNextEmpty && len(bytes.TrimSpace(line)) == 0 {
-					skipNextEmpty = false
-					continue
-				}
-				skipNextEmpty = false
+			} else if i >= markers.EndLine {
 				result = append(result, line)
 			}
 			// 跳过 StartLine+1 到 EndLine-1 之间的内容
@@ -152,9 +249,9 @@ func (h *MarkerHandler) ExtractExistingTOC(content []byte) string {
 		tocLines = append(tocLines, string(lines[i]))
 	}
 
-	// 去除首尾空行
+	// 去除首尾空行和折叠块
 	result := strings.TrimSpace(strings.Join(tocLines, "\n"))
-	return result
+	return unwrapDetails(result)
 }
 
 // FindFirstHeading 查找第一个标题所在行 (0-based)
@@ -224,7 +321,7 @@ func (h *MarkerHandler) InsertTOCAfterFirstHeading(content []byte, toc string) [
 			result = append(result, []byte(""))
 			result = append(result, []byte(h.marker))
 			result = append(result, []byte(""))
-			result = append(result, []byte(toc))
+			result = append(result, []byte(h.wrap(toc)))
 			result = append(result, []byte(""))
 			result = append(result, []byte(h.marker))
 		}
@@ -235,7 +332,7 @@ func (h *MarkerHandler) InsertTOCAfterFirstHeading(content []byte, toc string) [
 		var newResult [][]byte
 		newResult = append(newResult, []byte(h.marker))
 		newResult = append(newResult, []byte(""))
-		newResult = append(newResult, []byte(toc))
+		newResult = append(newResult, []byte(h.wrap(toc)))
 		newResult = append(newResult, []byte(""))
 		newResult = append(newResult, []byte(h.marker))
 		newResult = append(newResult, []byte(""))
@@ -250,6 +347,7 @@ func (h *MarkerHandler) InsertTOCAfterFirstHeading(content []byte, toc string) [
 type SectionTOC struct {
 	H1Line int    // H1 所在行号 (0-based)
 	TOC    string // 该章节的 TOC 内容
+	Marker string // 开始标记行 (保留原有的内联选项)，为空时使用默认标记
 }
 
 // FindH1Lines 查找所有 H1 标题的行号 (0-based)
@@ -301,10 +399,10 @@ func (h *MarkerHandler) InsertSectionTOCs(content []byte, sectionTOCs []SectionT
 	var result [][]byte
 
 	// 创建 H1 行号到 TOC 的映射
-	h1ToTOC := make(map[int]string)
+	h1ToTOC := make(map[int]SectionTOC)
 	for _, st := range sectionTOCs {
 		if st.TOC != "" {
-			h1ToTOC[st.H1Line] = st.TOC
+			h1ToTOC[st.H1Line] = st
 		}
 	}
 
@@ -326,14 +424,19 @@ func (h *MarkerHandler) InsertSectionTOCs(content []byte, sectionTOCs []SectionT
 		result = append(result, line)
 
 		// 检查是否需要在此行后插入 TOC
-		if toc, ok := h1ToTOC[i]; ok {
-			result = append(result, []byte(""))       // 空行（开始标记前）
-			result = append(result, []byte(h.marker)) // <!--TOC-->
-			result = append(result, []byte(""))       // 空行（开始标记后）
-			result = append(result, []byte(toc))      // TOC 内容
-			result = append(result, []byte(""))       // 空行（结束标记前）
-			result = append(result, []byte(h.marker)) // <!--TOC-->
-			result = append(result, []byte(""))       // 空行（结束标记后）
+		if st, ok := h1ToTOC[i]; ok {
+			startMarker := st.Marker
+			if startMarker == "" {
+				startMarker = h.marker
+			}
+			st.TOC = h.wrap(st.TOC)
+			result = append(result, []byte(""))          // 空行（开始标记前）
+			result = append(result, []byte(startMarker)) // <!--TOC ...-->
+			result = append(result, []byte(""))          // 空行（开始标记后）
+			result = append(result, []byte(st.TOC))      // TOC 内容
+			result = append(result, []byte(""))          // 空行（结束标记前）
+			result = append(result, []byte(h.marker))    // <!--TOC-->
+			result = append(result, []byte(""))          // 空行（结束标记后）
 		}
 	}
 
@@ -350,7 +453,6 @@ func (h *MarkerHandler) UpdateSectionTOCs(content []byte, sectionTOCs []SectionT
 	}
 
 	lines := bytes.Split(content, []byte("\n"))
-	markerBytes := []byte(h.marker)
 
 	// 找到所有现有的 TOC 区块 (成对的 <!--TOC-->)
 	type tocBlock struct {
@@ -361,8 +463,7 @@ func (h *MarkerHandler) UpdateSectionTOCs(content []byte, sectionTOCs []SectionT
 
 	var pendingStart = -1
 	for i, line := range lines {
-		trimmed := bytes.TrimSpace(line)
-		if bytes.Equal(trimmed, markerBytes) {
+		if h.IsMarker(line) {
 			if pendingStart == -1 {
 				pendingStart = i
 			} else {
@@ -419,11 +520,10 @@ func (h *MarkerHandler) UpdateSectionTOCs(content []byte, sectionTOCs []SectionT
 }
 
 // CleanTOCBlocks 删除所有 TOC 块，返回干净的内容
-// 删除的内容包括：TOC 块本身 + 块前的一个空行 + 块后的一个空行
+// 删除的内容包括：TOC 块本身 (含标记之间的 <details> 折叠块) + 块前的一个空行 + 块后的一个空行
 // 确保 H1 和 H2 之间只保留原始的一个空行（如果有）
 func (h *MarkerHandler) CleanTOCBlocks(content []byte) ([]byte, []TOCBlockInfo) {
 	lines := bytes.Split(content, []byte("\n"))
-	markerBytes := []byte(h.marker)
 
 	// 找到所有现有的 TOC 区块 (成对的 <!--TOC-->)
 	type tocBlock struct {
@@ -434,8 +534,7 @@ func (h *MarkerHandler) CleanTOCBlocks(content []byte) ([]byte, []TOCBlockInfo)
 
 	var pendingStart = -1
 	for i, line := range lines {
-		trimmed := bytes.TrimSpace(line)
-		if bytes.Equal(trimmed, markerBytes) {
+		if h.IsMarker(line) {
 			if pendingStart == -1 {
 				pendingStart = i
 			} else {
@@ -482,9 +581,12 @@ func (h *MarkerHandler) CleanTOCBlocks(content []byte) ([]byte, []TOCBlockInfo)
 			deleteLines[block.endLine+1] = true
 		}
 
+		options, _ := h.ParseMarkerLine(lines[block.startLine])
 		blockInfos = append(blockInfos, TOCBlockInfo{
 			StartLine: block.startLine,
 			EndLine:   block.endLine,
+			Marker:    string(lines[block.startLine]),
+			Options:   options,
 		})
 	}
 
@@ -501,13 +603,15 @@ func (h *MarkerHandler) CleanTOCBlocks(content []byte) ([]byte, []TOCBlockInfo)
 
 // TOCBlockInfo 记录 TOC 块的位置信息
 type TOCBlockInfo struct {
-	StartLine int // 开始行 (0-based)
-	EndLine   int // 结束行 (0-based)
+	StartLine int    // 开始行 (0-based)
+	EndLine   int    // 结束行 (0-based)
+	Marker    string // 开始标记行原文 (保留缩进等修饰)
+	Options   string // 开始标记中的内联选项原文
 }
 
 // CalcTOCBlockLines 计算插入一个 TOC 块会增加多少行
 // 格式：空行 + <!--TOC--> + 空行 + TOC内容 + 空行 + <!--TOC--> + 空行
-// 返回：6 + TOC内容行数
+// 返回：6 + TOC内容行数；TOC 折叠时使用 MarkerHandler.BlockLines 计入折叠块的行数
 func CalcTOCBlockLines(tocContent string) int {
 	if tocContent == "" {
 		return 0
@@ -516,13 +620,20 @@ func CalcTOCBlockLines(tocContent string) int {
 	return 6 + contentLines // 1(空行) + 1(开始标记) + 1(空行) + N(内容) + 1(空行) + 1(结束标记) + 1(空行)
 }
 
+// BlockLines 计算插入一个 TOC 块会增加多少行，包含折叠块的 <details>、<summary> 和空行
+func (h *MarkerHandler) BlockLines(toc string) int {
+	if toc == "" {
+		return 0
+	}
+	return CalcTOCBlockLines(h.wrap(toc))
+}
+
 // ==================== Enhanced Marker Handling (解决单个标记问题) ====================
 
 // FindAllMarkers 查找所有 TOC 标记位置
 // 返回所有标记的行号列表
 func (h *MarkerHandler) FindAllMarkers(content []byte) []int {
 	lines := bytes.Split(content, []byte("\n"))
-	markerBytes := []byte(h.marker)
 
 	// 找到 frontmatter 结束位置
 	frontmatterEnd := FindFrontmatterEnd(lines)
@@ -533,8 +644,7 @@ func (h *MarkerHandler) FindAllMarkers(content []byte) []int {
 
 	var positions []int
 	for i := startLine; i < len(lines); i++ {
-		trimmed := bytes.TrimSpace(lines[i])
-		if bytes.Equal(trimmed, markerBytes) {
+		if h.IsMarker(lines[i]) {
 			positions = append(positions, i)
 		}
 	}
@@ -573,7 +683,7 @@ func (h *MarkerHandler) insertTOCAfterSingleMarker(content []byte, markerLine in
 		if i == markerLine {
 			// 在标记后插入 TOC
 			result = append(result, []byte(""))
-			result = append(result, []byte(toc))
+			result = append(result, []byte(h.wrap(toc)))
 			result = append(result, []byte(""))
 			result = append(result, []byte(h.marker))
 		}
@@ -605,7 +715,7 @@ func (h *MarkerHandler) insertTOCWithExtraMarkersCleanup(content []byte, allMark
 	// 添加第一个标记和 TOC 内容
 	result = append(result, lines[allMarkers[0]])
 	result = append(result, []byte(""))
-	result = append(result, []byte(toc))
+	result = append(result, []byte(h.wrap(toc)))
 	result = append(result, []byte(""))
 
 	// 添加第二个标记（跳过两个标记之间的所有内容）
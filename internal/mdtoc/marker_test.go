@@ -102,6 +102,28 @@ Content`,
 <!--TOC-->
 Content`,
 		},
+		{
+			name: "two markers - blank line after end marker preserved",
+			content: `# Title
+
+<!--TOC-->
+
+Old TOC
+
+<!--TOC-->
+
+## Section`,
+			toc: "- [Section](#section)",
+			expected: `# Title
+
+<!--TOC-->
+
+- [Section](#section)
+
+<!--TOC-->
+
+## Section`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1114,3 +1136,140 @@ func TestRealWorldScenario(t *testing.T) {
 		t.Errorf("Expected 2 markers, got %d", markerCount)
 	}
 }
+
+func TestMarkerHandler_ParseMarkerLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		options  string
+		isMarker bool
+	}{
+		{"plain marker", "<!--TOC-->", "", true},
+		{"plain marker with spaces", "  <!--TOC-->  ", "", true},
+		{"marker with options", "<!--TOC max-level=2 ordered global-->", "max-level=2 ordered global", true},
+		{"marker with padded options", "<!--TOC  max-level=2  -->", "max-level=2", true},
+		{"marker with trailing space only", "<!--TOC -->", "", true},
+		{"different comment", "<!--TOCTREE-->", "", false},
+		{"regular comment", "<!-- comment -->", "", false},
+		{"text", "TOC", "", false},
+		{"unclosed", "<!--TOC max-level=2", "", false},
+		{"lookalike comment", "<!--TOC below is generated-->", "", false},
+		{"unknown option", "<!--TOC depth=2-->", "", false},
+	}
+
+	h := NewMarkerHandler(DefaultMarker)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, ok := h.ParseMarkerLine([]byte(tt.line))
+			if ok != tt.isMarker {
+				t.Errorf("ParseMarkerLine(%q) ok = %v, want %v", tt.line, ok, tt.isMarker)
+			}
+			if options != tt.options {
+				t.Errorf("ParseMarkerLine(%q) options = %q, want %q", tt.line, options, tt.options)
+			}
+		})
+	}
+}
+
+func TestParseMarkerOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Options
+		wantErr  bool
+	}{
+		{
+			name:     "empty",
+			input:    "",
+			expected: DefaultOptions(),
+		},
+		{
+			name:  "levels",
+			input: "min-level=2 max-level=4",
+			expected: Options{
+				MinLevel: 2, MaxLevel: 4, SectionTOC: true, ShowAnchor: true,
+			},
+		},
+		{
+			name:  "ordered global",
+			input: "ordered global",
+			expected: Options{
+				MinLevel: 1, MaxLevel: 3, Ordered: true, SectionTOC: false, ShowAnchor: true,
+			},
+		},
+		{
+			name:  "ordered false and mode section",
+			input: "ordered=false mode=section",
+			expected: Options{
+				MinLevel: 1, MaxLevel: 3, Ordered: false, SectionTOC: true, ShowAnchor: true,
+			},
+		},
+		{
+			name:    "unknown option",
+			input:   "depth=2",
+			wantErr: true,
+		},
+		{
+			name:    "missing level value",
+			input:   "max-level",
+			wantErr: true,
+		},
+		{
+			name:    "invalid level value",
+			input:   "max-level=deep",
+			wantErr: true,
+		},
+		{
+			name:    "invalid mode",
+			input:   "mode=sidebar",
+			wantErr: true,
+		},
+		{
+			name:  "mermaid format",
+			input: "format=mermaid global",
+			expected: Options{
+				MinLevel: 1, MaxLevel: 3, SectionTOC: false, ShowAnchor: true, Format: FormatMermaid,
+			},
+		},
+		{
+			name:    "invalid format",
+			input:   "format=svg",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseMarkerOptions(tt.input)
+			if err == nil {
+				var opts Options
+				opts, err = doc.Apply(DefaultOptions())
+				if err == nil && opts != tt.expected {
+					t.Errorf("ParseMarkerOptions(%q) = %+v, want %+v", tt.input, opts, tt.expected)
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseMarkerOptions(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMarkerHandler_FindMarkers_WithOptions(t *testing.T) {
+	content := "# Title\n<!--TOC max-level=2-->\nOld\n<!--TOC-->\n## Section"
+	h := NewMarkerHandler(DefaultMarker)
+
+	markers := h.FindMarkers([]byte(content))
+	if !markers.Found || markers.StartLine != 1 || markers.EndLine != 3 {
+		t.Errorf("FindMarkers() = %+v, want start 1 end 3", markers)
+	}
+	if markers.Options != "max-level=2" {
+		t.Errorf("FindMarkers() Options = %q, want %q", markers.Options, "max-level=2")
+	}
+
+	// InsertTOC 原样保留开始标记
+	got := string(h.InsertTOC([]byte(content), "- [Section](#section)"))
+	if !strings.Contains(got, "<!--TOC max-level=2-->\n\n- [Section](#section)\n\n<!--TOC-->") {
+		t.Errorf("InsertTOC() should preserve marker line, got:\n%s", got)
+	}
+}
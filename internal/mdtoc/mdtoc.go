@@ -1,6 +1,9 @@
 package mdtoc
 
 import (
+	"bytes"
+	"cmp"
+	"fmt"
 	"os"
 	"strings"
 )
@@ -15,14 +18,44 @@ type TOC struct {
 
 // New 创建新的 TOC 实例
 func New(opts Options) *TOC {
+	// HTML 文档没有 Markdown 的 H1 章节结构，只支持全局模式
+	if opts.HTMLInput {
+		opts.SectionTOC = false
+	}
+
+	marker := NewMarkerHandler(DefaultMarker)
+	if opts.Collapse == CollapseDetails || opts.Collapse == CollapseGroups {
+		marker.SetCollapsible(cmp.Or(opts.Summary, DefaultSummary))
+	}
 	return &TOC{
 		parser:    NewParser(opts),
 		generator: NewGenerator(opts),
-		marker:    NewMarkerHandler(DefaultMarker),
+		marker:    marker,
 		options:   opts,
 	}
 }
 
+// forDocument 返回应用文档 frontmatter 设置后的 TOC 实例
+// 文档没有 TOC 设置时返回自身，设置 toc: false 时返回 nil
+func (t *TOC) forDocument(content []byte) (*TOC, error) {
+	doc, err := ParseFrontmatter(content)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Disabled() {
+		return nil, nil
+	}
+	if doc.IsZero() {
+		return t, nil
+	}
+
+	opts, err := doc.Apply(t.options)
+	if err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	return New(opts), nil
+}
+
 // GenerateFromFile 从文件生成 TOC 字符串
 func (t *TOC) GenerateFromFile(filename string) (string, error) {
 	content, err := os.ReadFile(filename)
@@ -33,12 +66,22 @@ func (t *TOC) GenerateFromFile(filename string) (string, error) {
 }
 
 // GenerateFromContent 从内容生成 TOC 字符串
+// 文档 frontmatter 中的 TOC 设置会覆盖 Options
 func (t *TOC) GenerateFromContent(content []byte) (string, error) {
+	d, err := t.forDocument(content)
+	if err != nil || d == nil {
+		return "", err
+	}
+	return d.generateFromContent(content)
+}
+
+// generateFromContent 从内容生成 TOC 字符串 (不处理 frontmatter 设置)
+func (t *TOC) generateFromContent(content []byte) (string, error) {
 	headers, err := t.parser.Parse(content)
 	if err != nil {
 		return "", err
 	}
-	return t.generator.Generate(headers), nil
+	return t.generator.Generate(headers)
 }
 
 // GenerateSectionTOCs 生成章节模式的 TOC (每个 H1 有独立的子目录)
@@ -55,7 +98,10 @@ func (t *TOC) GenerateSectionTOCs(content []byte) ([]SectionTOC, error) {
 	// 为每个章节生成 TOC
 	var sectionTOCs []SectionTOC
 	for _, section := range sections {
-		toc := t.generator.GenerateSection(section)
+		toc, err := t.generator.GenerateSection(section)
+		if err != nil {
+			return nil, err
+		}
 		if toc != "" {
 			sectionTOCs = append(sectionTOCs, SectionTOC{
 				H1Line: section.Title.Line - 1, // 转换为 0-based
@@ -70,6 +116,13 @@ func (t *TOC) GenerateSectionTOCs(content []byte) ([]SectionTOC, error) {
 // GenerateSectionTOCsWithOffset 生成章节模式的 TOC，预计算偏移量使行号正确
 // 在干净内容（已移除旧 TOC）上解析标题，计算 TOC 插入后的正确行号
 func (t *TOC) GenerateSectionTOCsWithOffset(cleanContent []byte) ([]SectionTOC, error) {
+	return t.generateSectionTOCsWithOffset(cleanContent, nil)
+}
+
+// generateSectionTOCsWithOffset 生成章节模式的 TOC
+// markers 是章节序号 (第几个 H1，0-based) 到原有开始标记行的映射，
+// 标记中的内联选项只作用于该章节的 TOC，标记行会原样保留
+func (t *TOC) generateSectionTOCsWithOffset(cleanContent []byte, markers map[int]string) ([]SectionTOC, error) {
 	// 在干净内容上解析所有标题（基准行号）
 	headers, err := t.parser.ParseAllHeaders(cleanContent)
 	if err != nil {
@@ -78,34 +131,50 @@ func (t *TOC) GenerateSectionTOCsWithOffset(cleanContent []byte) ([]SectionTOC,
 
 	// 按 H1 分割成章节
 	sections := SplitSections(headers)
-
-	// 第一遍：临时禁用行号，计算每个 TOC 块的行数
-	origLineNumber := t.options.LineNumber
-	t.options.LineNumber = false
-	tempGenerator := NewGenerator(t.options)
+	cleanLines := bytes.Split(cleanContent, []byte("\n"))
 
 	type sectionInfo struct {
 		section      *Section
-		tocLines     int // TOC 块总行数
-		originalLine int // H1 在干净内容中的原始行号 (0-based)
+		generator    *Generator // 该章节使用的生成器 (可能带有标记内联选项)
+		marker       string     // 开始标记行
+		tocLines     int        // TOC 块总行数
+		originalLine int        // H1 在干净内容中的原始行号 (0-based)
 	}
 	var infos []sectionInfo
 
-	for _, section := range sections {
-		toc := tempGenerator.GenerateSection(section)
+	// 第一遍：禁用行号，计算每个 TOC 块的行数
+	for i, section := range sections {
+		opts := t.options
+		marker := markers[i]
+		if options, _ := t.marker.ParseMarkerLine([]byte(marker)); options != "" {
+			if opts, err = t.markerOptions(options); err != nil {
+				return nil, err
+			}
+		}
+
+		tempOpts := opts
+		tempOpts.LineNumber = false
+		toc, err := NewGenerator(tempOpts).GenerateSection(section)
+		if err != nil {
+			return nil, err
+		}
 		if toc != "" {
-			tocBlockLines := CalcTOCBlockLines(toc)
+			tocBlockLines := t.marker.BlockLines(toc)
+			// InsertSectionTOCs 会移除 H1 后原有的空行，需要从偏移量中扣除
+			h1Line := section.Title.Line - 1
+			if h1Line+1 < len(cleanLines) && len(bytes.TrimSpace(cleanLines[h1Line+1])) == 0 {
+				tocBlockLines--
+			}
 			infos = append(infos, sectionInfo{
 				section:      section,
+				generator:    NewGenerator(opts),
+				marker:       marker,
 				tocLines:     tocBlockLines,
 				originalLine: section.Title.Line - 1, // 转换为 0-based
 			})
 		}
 	}
 
-	// 恢复行号设置
-	t.options.LineNumber = origLineNumber
-
 	// 第二遍：计算累积偏移量并生成带正确行号的 TOC
 	var sectionTOCs []SectionTOC
 	cumulativeOffset := 0
@@ -124,11 +193,15 @@ func (t *TOC) GenerateSectionTOCsWithOffset(cleanContent []byte) ([]SectionTOC,
 		}
 
 		// 用调整后的行号生成 TOC
-		toc := t.generator.GenerateSection(adjustedSection)
+		toc, err := info.generator.GenerateSection(adjustedSection)
+		if err != nil {
+			return nil, err
+		}
 		if toc != "" {
 			sectionTOCs = append(sectionTOCs, SectionTOC{
 				H1Line: info.originalLine, // 使用原始行号定位插入位置
 				TOC:    toc,
+				Marker: info.marker,
 			})
 		}
 
@@ -139,6 +212,66 @@ func (t *TOC) GenerateSectionTOCsWithOffset(cleanContent []byte) ([]SectionTOC,
 	return sectionTOCs, nil
 }
 
+// sectionMarkers 找出原内容中的 TOC 块，将其开始标记行映射到所属章节 (第几个 H1，0-based)
+// 标记行原样保留，包括内联选项和缩进等修饰
+func (t *TOC) sectionMarkers(content []byte, blocks []TOCBlockInfo) (map[int]string, error) {
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+
+	headers, err := t.parser.ParseAllHeaders(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var h1Lines []int // H1 行号 (0-based)
+	for _, h := range headers {
+		if h.Level == 1 {
+			h1Lines = append(h1Lines, h.Line-1)
+		}
+	}
+
+	markers := make(map[int]string)
+	for _, b := range blocks {
+		// TOC 块属于它之前最近的 H1
+		section := -1
+		for i, line := range h1Lines {
+			if line < b.StartLine {
+				section = i
+			}
+		}
+		if section >= 0 {
+			markers[section] = b.Marker
+		}
+	}
+	return markers, nil
+}
+
+// markerOptions 返回应用标记内联选项后的 Options
+func (t *TOC) markerOptions(options string) (Options, error) {
+	doc, err := ParseMarkerOptions(options)
+	if err != nil {
+		return t.options, fmt.Errorf("TOC 标记: %w", err)
+	}
+	opts, err := doc.Apply(t.options)
+	if err != nil {
+		return t.options, fmt.Errorf("TOC 标记: %w", err)
+	}
+	return opts, nil
+}
+
+// withMarkerOptions 返回应用标记内联选项后的 TOC 实例
+func (t *TOC) withMarkerOptions(options string) (*TOC, error) {
+	if options == "" {
+		return t, nil
+	}
+	opts, err := t.markerOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return New(opts), nil
+}
+
 // adjustHeader 创建调整行号后的 Header 副本
 func adjustHeader(h *Header, offset int) *Header {
 	return &Header{
@@ -150,8 +283,31 @@ func adjustHeader(h *Header, offset int) *Header {
 	}
 }
 
+// Preview 生成 TOC 预览 (用于 stdout 输出)
+// 根据 SectionTOC 选择章节模式或全局模式，文档 frontmatter 中的 TOC 设置会覆盖 Options
+func (t *TOC) Preview(content []byte) (string, error) {
+	d, err := t.forDocument(content)
+	if err != nil || d == nil {
+		return "", err
+	}
+	if d.options.SectionTOC {
+		return d.generateSectionTOCsPreview(content)
+	}
+	return d.generateFromContent(content)
+}
+
 // GenerateSectionTOCsPreview 生成章节模式的 TOC 预览 (用于 stdout 输出)
+// 文档 frontmatter 中的 TOC 设置会覆盖 Options
 func (t *TOC) GenerateSectionTOCsPreview(content []byte) (string, error) {
+	d, err := t.forDocument(content)
+	if err != nil || d == nil {
+		return "", err
+	}
+	return d.generateSectionTOCsPreview(content)
+}
+
+// generateSectionTOCsPreview 生成章节模式的 TOC 预览 (不处理 frontmatter 设置)
+func (t *TOC) generateSectionTOCsPreview(content []byte) (string, error) {
 	// 解析所有标题
 	headers, err := t.parser.ParseAllHeaders(content)
 	if err != nil {
@@ -163,10 +319,12 @@ func (t *TOC) GenerateSectionTOCsPreview(content []byte) (string, error) {
 
 	var sb strings.Builder
 	for i, section := range sections {
-		toc := t.generator.GenerateSection(section)
+		toc, err := t.generator.GenerateSection(section)
+		if err != nil {
+			return "", err
+		}
 		if toc != "" {
-			sb.WriteString("### ")
-			sb.WriteString(section.Title.Text)
+			sb.WriteString(t.generator.SectionTitle(section.Title.Text))
 			sb.WriteString("\n\n")
 			sb.WriteString(toc)
 			if i < len(sections)-1 {
@@ -180,43 +338,122 @@ func (t *TOC) GenerateSectionTOCsPreview(content []byte) (string, error) {
 
 // UpdateFile 原地更新文件中的 TOC
 // 如果文件没有 TOC 标记，会自动在第一个标题后插入
-func (t *TOC) UpdateFile(filename string) error {
+// 返回文件是否被改写，内容无变化时不写入
+func (t *TOC) UpdateFile(filename string) (bool, error) {
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	newContent, err := t.UpdateContent(content)
+	if err != nil {
+		return false, err
 	}
 
-	var newContent []byte
+	return WriteFile(filename, content, newContent)
+}
+
+// UpdateContent 计算更新 TOC 后的文档内容 (不写入磁盘)
+// 与 UpdateFile 使用相同的处理流程，可用于检查 TOC 是否过期
+// 文档 frontmatter 中的 TOC 设置会覆盖 Options，设置 toc: false 时内容保持不变
+func (t *TOC) UpdateContent(content []byte) ([]byte, error) {
+	d, err := t.forDocument(content)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return content, nil
+	}
+	return d.updateContent(content)
+}
+
+// updateContent 计算更新 TOC 后的文档内容 (不处理 frontmatter 设置)
+func (t *TOC) updateContent(content []byte) ([]byte, error) {
+	// HTML 文档只更新已有标记处的 TOC，不自动插入
+	if t.options.HTMLInput && !t.marker.FindMarkers(content).Found {
+		return content, nil
+	}
+
+	// 第一个标记中的 global/section 选项决定整个文档的模式
+	if markers := t.marker.FindMarkers(content); markers.Options != "" {
+		opts, err := t.markerOptions(markers.Options)
+		if err != nil {
+			return nil, err
+		}
+		if opts.SectionTOC != t.options.SectionTOC {
+			modeOpts := t.options
+			modeOpts.SectionTOC = opts.SectionTOC
+			t = New(modeOpts)
+		}
+	}
 
 	if t.options.SectionTOC {
 		// 章节模式：在每个 H1 后插入独立的子目录
 		// 先清理现有 TOC 块，获取干净内容
-		cleanContent, _ := t.marker.CleanTOCBlocks(content)
+		cleanContent, blocks := t.marker.CleanTOCBlocks(content)
 
-		// 使用预计算偏移量的方法生成 TOC
-		sectionTOCs, err := t.GenerateSectionTOCsWithOffset(cleanContent)
+		// 原样保留开始标记行
+		markers, err := t.sectionMarkers(content, blocks)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// 在干净内容上插入新的 TOC
-		newContent = t.marker.InsertSectionTOCs(cleanContent, sectionTOCs)
-	} else {
-		// 普通模式：在 <!--TOC--> 标记处插入完整 TOC
-		toc, err := t.GenerateFromContent(content)
+		// 使用预计算偏移量的方法生成 TOC
+		sectionTOCs, err := t.generateSectionTOCsWithOffset(cleanContent, markers)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		markers := t.marker.FindMarkers(content)
-		if markers.Found {
-			newContent = t.marker.InsertTOC(content, toc)
-		} else {
-			newContent = t.marker.InsertTOCAfterFirstHeading(content, toc)
+		// 在干净内容上插入新的 TOC
+		return t.marker.InsertSectionTOCs(cleanContent, sectionTOCs), nil
+	}
+
+	// 普通模式：在 <!--TOC--> 标记处插入完整 TOC
+	// 行号基于插入前的内容计算，TOC 块行数变化后行号会偏移；
+	// TOC 块行数与行号无关，因此在结果上再执行一遍即可得到稳定的行号
+	newContent, err := t.updateGlobal(content)
+	if err != nil || bytes.Equal(newContent, content) {
+		return newContent, err
+	}
+	return t.updateGlobal(newContent)
+}
+
+// updateGlobal 在 <!--TOC--> 标记处 (或第一个标题后) 插入完整 TOC
+// 标记中的内联选项作用于生成的 TOC，标记行原样保留
+func (t *TOC) updateGlobal(content []byte) ([]byte, error) {
+	markers := t.marker.FindMarkers(content)
+
+	g, err := t.withMarkerOptions(markers.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	// HTML 文档中没有 id 的标题写入生成的 id，保证 <nav> 中的链接能够跳转
+	if t.options.HTMLInput {
+		if content, err = g.parser.addHTMLHeadingIDs(content); err != nil {
+			return nil, err
 		}
 	}
 
-	return os.WriteFile(filename, newContent, 0644)
+	toc, err := g.generateFromContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if markers.Found {
+		return t.marker.InsertTOC(content, toc), nil
+	}
+	return t.marker.InsertTOCAfterFirstHeading(content, toc), nil
+}
+
+// IsUpToDate 检查内容中的 TOC 是否为最新
+// 当 UpdateContent 的结果与原内容完全一致时返回 true
+func (t *TOC) IsUpToDate(content []byte) (bool, error) {
+	newContent, err := t.UpdateContent(content)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(content, newContent), nil
 }
 
 // HasMarker 检查文件是否包含 TOC 标记
@@ -237,17 +474,24 @@ func (t *TOC) DeleteTOC(filename string) (bool, error) {
 		return false, err
 	}
 
-	cleanContent, blockInfos := t.marker.CleanTOCBlocks(content)
+	cleanContent, deleted := t.DeleteContent(content)
 
 	// 没有 TOC 块被删除
-	if len(blockInfos) == 0 {
+	if !deleted {
 		return false, nil
 	}
 
 	// 写入清理后的内容
-	if err := os.WriteFile(filename, cleanContent, 0644); err != nil {
+	if _, err := WriteFile(filename, content, cleanContent); err != nil {
 		return false, err
 	}
 
 	return true, nil
 }
+
+// DeleteContent 计算删除所有 TOC 块后的文档内容 (不写入磁盘)
+// 返回清理后的内容以及是否有 TOC 块被删除
+func (t *TOC) DeleteContent(content []byte) ([]byte, bool) {
+	cleanContent, blockInfos := t.marker.CleanTOCBlocks(content)
+	return cleanContent, len(blockInfos) > 0
+}
@@ -315,7 +315,7 @@ Old TOC content
 		}
 
 		toc := mdtoc.New(mdtoc.DefaultOptions())
-		if err := toc.UpdateFile(filePath); err != nil {
+		if _, err := toc.UpdateFile(filePath); err != nil {
 			t.Fatal(err)
 		}
 
@@ -343,7 +343,7 @@ Old TOC content
 		}
 
 		toc := mdtoc.New(mdtoc.DefaultOptions())
-		if err := toc.UpdateFile(filePath); err != nil {
+		if _, err := toc.UpdateFile(filePath); err != nil {
 			t.Fatal(err)
 		}
 
@@ -379,7 +379,7 @@ Old TOC content
 			SectionTOC: true,
 			ShowAnchor: true, // 写入文件时必须显示链接
 		})
-		if err := toc.UpdateFile(filePath); err != nil {
+		if _, err := toc.UpdateFile(filePath); err != nil {
 			t.Fatal(err)
 		}
 
@@ -784,9 +784,10 @@ Content here
 More content
 `,
 			// Line numbers reflect FINAL file (after TOC insertion)
-			// TOC block adds ~8 lines (空行+标记+空行+内容+空行+标记+空行)
-			// Section 1.1 at line 15, Section 1.2 at line 18
-			expectedLineNums: []string{`:15+`, `:18+`},
+			// TOC block adds 8 lines (空行+标记+空行+内容+空行+标记+空行)
+			// and replaces the blank line after H1
+			// Section 1.1 at line 14, Section 1.2 at line 17
+			expectedLineNums: []string{`:14+`, `:17+`},
 		},
 		{
 			name: "frontmatter with YAML comment should not affect line numbers",
@@ -803,8 +804,8 @@ First section content
 Second section content
 `,
 			// Line numbers reflect FINAL file (after TOC insertion)
-			// Section A at line 15, Section B at line 18
-			expectedLineNums: []string{`:15+`, `:18+`},
+			// Section A at line 14, Section B at line 17
+			expectedLineNums: []string{`:14+`, `:17+`},
 		},
 	}
 
@@ -822,7 +823,7 @@ Second section content
 				SectionTOC: true,
 				ShowAnchor: true,
 			})
-			if err := toc.UpdateFile(filePath); err != nil {
+			if _, err := toc.UpdateFile(filePath); err != nil {
 				t.Fatal(err)
 			}
 
@@ -923,7 +924,7 @@ More content
 		ShowAnchor: true,
 		LineNumber: true,
 	})
-	if err := toc.UpdateFile(filePath); err != nil {
+	if _, err := toc.UpdateFile(filePath); err != nil {
 		t.Fatal(err)
 	}
 
@@ -1009,3 +1010,269 @@ Content
 		t.Error("Section headers should be preserved")
 	}
 }
+
+// TestTOC_IsUpToDate 测试检查模式：在内存中比较更新结果，不写入文件
+func TestTOC_IsUpToDate(t *testing.T) {
+	content := []byte("# Title\n\n## Section 1\n\n## Section 2\n")
+
+	opts := mdtoc.DefaultOptions()
+	opts.LineNumber = true
+	toc := mdtoc.New(opts)
+
+	// 没有 TOC 的文件视为过期
+	upToDate, err := toc.IsUpToDate(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upToDate {
+		t.Error("IsUpToDate() should return false for file without TOC")
+	}
+
+	// 更新后的内容应视为最新
+	updated, err := toc.UpdateContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upToDate, err = toc.IsUpToDate(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !upToDate {
+		t.Errorf("IsUpToDate() should return true after UpdateContent, content:\n%s", updated)
+	}
+
+	// 修改标题后应视为过期
+	changed := []byte(strings.Replace(string(updated), "## Section 2", "## Section Two", 1))
+	upToDate, err = toc.IsUpToDate(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upToDate {
+		t.Error("IsUpToDate() should return false after headings changed")
+	}
+
+	// 全局模式同样适用
+	globalOpts := opts
+	globalOpts.SectionTOC = false
+	globalTOC := mdtoc.New(globalOpts)
+	globalUpdated, err := globalTOC.UpdateContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upToDate, err = globalTOC.IsUpToDate(globalUpdated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !upToDate {
+		t.Errorf("IsUpToDate() should return true in global mode, content:\n%s", globalUpdated)
+	}
+}
+
+// TestTOC_DeleteContent 测试在内存中删除 TOC 块
+func TestTOC_DeleteContent(t *testing.T) {
+	toc := mdtoc.New(mdtoc.DefaultOptions())
+
+	content := []byte("# Title\n\n<!--TOC-->\n\n- [Section](#section)\n\n<!--TOC-->\n\n## Section\n")
+	cleaned, deleted := toc.DeleteContent(content)
+	if !deleted {
+		t.Error("DeleteContent() should report deleted blocks")
+	}
+	if strings.Contains(string(cleaned), "<!--TOC-->") {
+		t.Errorf("DeleteContent() should remove markers, got:\n%s", cleaned)
+	}
+
+	plain := []byte("# Title\n\n## Section\n")
+	cleaned, deleted = toc.DeleteContent(plain)
+	if deleted {
+		t.Error("DeleteContent() should report nothing deleted for file without TOC")
+	}
+	if string(cleaned) != string(plain) {
+		t.Error("DeleteContent() should return original content when nothing deleted")
+	}
+
+	// 类似标记的普通注释不能与真正的标记配对，否则其间的正文会被删除
+	lookalike := []byte("# Title\n\n<!--TOC below is generated-->\n\nimportant text\n\n<!--TOC-->\n\n- [Section](#section)\n\n<!--TOC-->\n\n## Section\n")
+	cleaned, deleted = toc.DeleteContent(lookalike)
+	if !deleted {
+		t.Error("DeleteContent() should report deleted blocks")
+	}
+	if !strings.Contains(string(cleaned), "<!--TOC below is generated-->\n\nimportant text\n") {
+		t.Errorf("DeleteContent() should keep lookalike comment and prose, got:\n%s", cleaned)
+	}
+	if strings.Contains(string(cleaned), "<!--TOC-->") || strings.Contains(string(cleaned), "[Section]") {
+		t.Errorf("DeleteContent() should remove the real TOC block, got:\n%s", cleaned)
+	}
+}
+
+// TestTOC_FrontmatterOverrides 测试文档 frontmatter 覆盖 TOC 选项
+func TestTOC_FrontmatterOverrides(t *testing.T) {
+	t.Run("toc false leaves document untouched", func(t *testing.T) {
+		content := []byte("---\nlayout: home\ntoc: false\n---\n# Title\n\n## Section\n")
+		toc := mdtoc.New(mdtoc.DefaultOptions())
+
+		updated, err := toc.UpdateContent(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(updated) != string(content) {
+			t.Errorf("UpdateContent() should not modify document with toc: false, got:\n%s", updated)
+		}
+
+		preview, err := toc.Preview(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if preview != "" {
+			t.Errorf("Preview() should be empty with toc: false, got:\n%s", preview)
+		}
+	})
+
+	t.Run("toc_mode global", func(t *testing.T) {
+		content := []byte("---\ntoc_mode: global\n---\n# Title\n\n## Section\n")
+		toc := mdtoc.New(mdtoc.DefaultOptions())
+
+		updated, err := toc.UpdateContent(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 全局模式包含 H1 标题
+		if !strings.Contains(string(updated), "- [Title](#title)") {
+			t.Errorf("UpdateContent() should use global mode, got:\n%s", updated)
+		}
+	})
+
+	t.Run("toc_max_level and toc_ordered", func(t *testing.T) {
+		content := []byte("---\ntoc_max_level: 2\ntoc_ordered: true\n---\n# Title\n\n## Section\n\n### Detail\n")
+		toc := mdtoc.New(mdtoc.DefaultOptions())
+
+		preview, err := toc.Preview(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(preview, "1. [Section](#section)") {
+			t.Errorf("Preview() should use ordered list, got:\n%s", preview)
+		}
+		if strings.Contains(preview, "Detail") {
+			t.Errorf("Preview() should respect toc_max_level, got:\n%s", preview)
+		}
+	})
+
+	t.Run("frontmatter owned by other tools is ignored", func(t *testing.T) {
+		content := []byte("---\ntitle: [unclosed\ntoc:\n  depth: 2\n---\n# Title\n\n## Section\n")
+		toc := mdtoc.New(mdtoc.DefaultOptions())
+
+		updated, err := toc.UpdateContent(content)
+		if err != nil {
+			t.Fatalf("UpdateContent() error = %v, want unrelated frontmatter ignored", err)
+		}
+		if !strings.Contains(string(updated), "[Section](#section)") {
+			t.Errorf("UpdateContent() should insert TOC, got:\n%s", updated)
+		}
+	})
+
+	t.Run("invalid frontmatter setting", func(t *testing.T) {
+		content := []byte("---\ntoc_mode: sidebar\n---\n# Title\n\n## Section\n")
+		toc := mdtoc.New(mdtoc.DefaultOptions())
+
+		if _, err := toc.UpdateContent(content); err == nil {
+			t.Error("UpdateContent() should fail with invalid toc_mode")
+		}
+	})
+}
+
+// TestTOC_MarkerOptions 测试 TOC 标记中的内联选项
+func TestTOC_MarkerOptions(t *testing.T) {
+	t.Run("global marker options", func(t *testing.T) {
+		content := []byte("# Title\n\n<!--TOC max-level=2 ordered global-->\n\n## A\n\n### A1\n\n## B\n")
+		toc := mdtoc.New(mdtoc.DefaultOptions())
+
+		updated, err := toc.UpdateContent(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(updated)
+
+		if !strings.Contains(got, "<!--TOC max-level=2 ordered global-->") {
+			t.Errorf("marker line should be preserved verbatim, got:\n%s", got)
+		}
+		if !strings.Contains(got, "1. [Title](#title)") {
+			t.Errorf("marker should switch to ordered global mode, got:\n%s", got)
+		}
+		if strings.Contains(got, "[A1]") {
+			t.Errorf("marker max-level should exclude H3, got:\n%s", got)
+		}
+
+		// 再次更新结果不变
+		upToDate, err := toc.IsUpToDate(updated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !upToDate {
+			t.Error("IsUpToDate() should be true after update")
+		}
+	})
+
+	t.Run("section marker options apply to one section", func(t *testing.T) {
+		content := []byte("# One\n\n<!--TOC max-level=2-->\n\n- old\n\n<!--TOC-->\n\n## A\n\n### A1\n\n# Two\n\n## B\n\n### B1\n")
+		toc := mdtoc.New(mdtoc.DefaultOptions())
+
+		updated, err := toc.UpdateContent(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(updated)
+
+		if !strings.Contains(got, "# One\n\n<!--TOC max-level=2-->\n\n- [A](#a)") {
+			t.Errorf("first section should keep marker options, got:\n%s", got)
+		}
+		if strings.Contains(got, "[A1]") {
+			t.Errorf("first section should exclude H3, got:\n%s", got)
+		}
+		if !strings.Contains(got, "[B1]") {
+			t.Errorf("second section should use default options, got:\n%s", got)
+		}
+
+		upToDate, err := toc.IsUpToDate(updated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !upToDate {
+			t.Errorf("IsUpToDate() should be true after update, got:\n%s", got)
+		}
+	})
+
+	t.Run("indented section marker is preserved verbatim", func(t *testing.T) {
+		content := []byte("# One\n\n  <!--TOC max-level=2-->\n\n- old\n\n<!--TOC-->\n\n## A\n\n### A1\n\n# Two\n\n## B\n")
+		toc := mdtoc.New(mdtoc.DefaultOptions())
+
+		updated, err := toc.UpdateContent(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(updated)
+
+		if !strings.Contains(got, "# One\n\n  <!--TOC max-level=2-->\n\n- [A](#a)") {
+			t.Errorf("indented marker line should be preserved verbatim, got:\n%s", got)
+		}
+		if strings.Contains(got, "[A1]") {
+			t.Errorf("indented marker options should still apply, got:\n%s", got)
+		}
+
+		upToDate, err := toc.IsUpToDate(updated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !upToDate {
+			t.Errorf("IsUpToDate() should be true after update, got:\n%s", got)
+		}
+	})
+
+	t.Run("invalid marker option", func(t *testing.T) {
+		content := []byte("# Title\n\n<!--TOC mode=sidebar-->\n\n## A\n")
+		toc := mdtoc.New(mdtoc.DefaultOptions())
+
+		if _, err := toc.UpdateContent(content); err == nil {
+			t.Error("UpdateContent() should fail with invalid marker option value")
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package mdtoc
+
+import (
+	"strconv"
+	"strings"
+)
+
+// mermaidEscaper 转义 Mermaid 节点文本
+// 文本放在双引号中，括号等形状字符无需转义；# 和引号使用 Mermaid 的实体编码，
+// 反引号会被识别为 Markdown 字符串，同样编码
+var mermaidEscaper = strings.NewReplacer(
+	"#", "#35;",
+	`"`, "#quot;",
+	"`", "#96;",
+	"\n", " ",
+)
+
+// generateMermaid 生成 Mermaid mindmap 代码块
+// 根节点为章节标题；全局模式下只有一个顶层标题时以其为根，否则使用 "TOC"
+func (g *Generator) generateMermaid(headers []*Header, title string) string {
+	roots := BuildTree(headers)
+	if title == "" && len(roots) == 1 {
+		title = roots[0].Text
+		roots = roots[0].Children
+	}
+	if title == "" {
+		title = "TOC"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("```mermaid\nmindmap\n")
+	sb.WriteString(`  root(("` + mermaidEscaper.Replace(title) + `"))`)
+
+	id := 0
+	var walk func(nodes []*OutlineNode, depth int)
+	walk = func(nodes []*OutlineNode, depth int) {
+		for _, n := range nodes {
+			id++
+			sb.WriteString("\n" + strings.Repeat("  ", depth))
+			sb.WriteString("n" + strconv.Itoa(id) + `["` + mermaidEscaper.Replace(n.Text) + `"]`)
+			walk(n.Children, depth+1)
+		}
+	}
+	walk(roots, 2)
+
+	sb.WriteString("\n```")
+	return sb.String()
+}
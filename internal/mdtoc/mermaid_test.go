@@ -0,0 +1,83 @@
+package mdtoc
+
+import (
+	"testing"
+)
+
+func TestGenerator_Mermaid(t *testing.T) {
+	headers := []*Header{
+		{Level: 1, Text: `Design "v2"`, AnchorLink: "design-v2", Line: 1, EndLine: 20},
+		{Level: 2, Text: "C# & `go` API", AnchorLink: "c--go-api", Line: 3, EndLine: 10},
+		{Level: 3, Text: "Types (draft)", AnchorLink: "types-draft", Line: 5, EndLine: 10},
+		{Level: 2, Text: "部署", AnchorLink: "部署", Line: 11, EndLine: 20},
+	}
+
+	t.Run("single top-level heading becomes root", func(t *testing.T) {
+		got := mustGenerate(t, NewGenerator(Options{MinLevel: 1, MaxLevel: 3, Format: FormatMermaid}), headers)
+		expected := "```mermaid\nmindmap\n" +
+			`  root(("Design #quot;v2#quot;"))
+    n1["C#35; & #96;go#96; API"]
+      n2["Types (draft)"]
+    n3["部署"]` + "\n```"
+		if got != expected {
+			t.Errorf("Generate() =\n%s\nwant\n%s", got, expected)
+		}
+	})
+
+	t.Run("multiple top-level headings", func(t *testing.T) {
+		got := mustGenerate(t, NewGenerator(Options{MinLevel: 2, MaxLevel: 2, Format: FormatMermaid}), []*Header{headers[1], headers[3]})
+		expected := "```mermaid\nmindmap\n" +
+			`  root(("TOC"))
+    n1["C#35; & #96;go#96; API"]
+    n2["部署"]` + "\n```"
+		if got != expected {
+			t.Errorf("Generate() =\n%s\nwant\n%s", got, expected)
+		}
+	})
+
+	t.Run("section title becomes root", func(t *testing.T) {
+		section := &Section{Title: headers[0], SubHeaders: headers[1:]}
+		got := mustGenerateSection(t, NewGenerator(Options{MinLevel: 1, MaxLevel: 3, Format: FormatMermaid}), section)
+		expected := "```mermaid\nmindmap\n" +
+			`  root(("Design #quot;v2#quot;"))
+    n1["C#35; & #96;go#96; API"]
+      n2["Types (draft)"]
+    n3["部署"]` + "\n```"
+		if got != expected {
+			t.Errorf("GenerateSection() =\n%s\nwant\n%s", got, expected)
+		}
+	})
+}
+
+func TestOutline_OPML(t *testing.T) {
+	outline := NewOutline()
+	outline.Files = append(outline.Files, &FileOutline{
+		Path: "docs/a.md",
+		Headers: BuildTree([]*Header{
+			{Level: 1, Text: `Q&A <"FAQ">`, AnchorLink: "qa-faq", Line: 1, EndLine: 9},
+			{Level: 2, Text: "Install", AnchorLink: "install", Line: 3, EndLine: 9},
+		}),
+	})
+
+	got, err := outline.OPML()
+	if err != nil {
+		t.Fatalf("OPML() error = %v", err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <title>docs/a.md</title>
+  </head>
+  <body>
+    <outline text="docs/a.md" type="link" url="docs/a.md">
+      <outline text="Q&amp;A &lt;&#34;FAQ&#34;&gt;" type="link" url="docs/a.md#qa-faq" line="1" endLine="9">
+        <outline text="Install" type="link" url="docs/a.md#install" line="3" endLine="9"></outline>
+      </outline>
+    </outline>
+  </body>
+</opml>
+`
+	if string(got) != expected {
+		t.Errorf("OPML() =\n%s\nwant\n%s", got, expected)
+	}
+}
@@ -0,0 +1,64 @@
+package mdtoc
+
+import (
+	"encoding/xml"
+)
+
+// opmlDocument OPML 2.0 文档
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+// opmlOutline OPML 大纲节点
+// type="link" 与 url 属性使大纲工具可以跳转到对应文件或锚点
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Type     string        `xml:"type,attr,omitempty"`
+	URL      string        `xml:"url,attr,omitempty"`
+	Line     int           `xml:"line,attr,omitempty"`
+	EndLine  int           `xml:"endLine,attr,omitempty"`
+	Children []opmlOutline `xml:"outline"`
+}
+
+// OPML 将大纲导出为 OPML 2.0 文档，供大纲和思维导图工具导入
+// 每个文件为一个顶层节点，标题按层级嵌套在其下；特殊字符由 encoding/xml 转义
+func (o *Outline) OPML() ([]byte, error) {
+	doc := opmlDocument{Version: "2.0", Title: "mc-mdtoc"}
+	if len(o.Files) == 1 {
+		doc.Title = o.Files[0].Path
+	}
+
+	var convert func(path string, nodes []*OutlineNode) []opmlOutline
+	convert = func(path string, nodes []*OutlineNode) []opmlOutline {
+		var result []opmlOutline
+		for _, n := range nodes {
+			result = append(result, opmlOutline{
+				Text:     n.Text,
+				Type:     "link",
+				URL:      path + "#" + n.Anchor,
+				Line:     n.Line,
+				EndLine:  n.EndLine,
+				Children: convert(path, n.Children),
+			})
+		}
+		return result
+	}
+
+	for _, f := range o.Files {
+		doc.Body = append(doc.Body, opmlOutline{
+			Text:     f.Path,
+			Type:     "link",
+			URL:      f.Path,
+			Children: convert(f.Path, f.Headers),
+		})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
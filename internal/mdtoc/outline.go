@@ -0,0 +1,116 @@
+package mdtoc
+
+import "fmt"
+
+// OutlineVersion 结构化大纲输出的格式版本
+// 字段含义或结构发生不兼容变化时递增，新增字段不改变版本
+const OutlineVersion = 1
+
+// Outline 结构化大纲输出的顶层对象
+type Outline struct {
+	Version int            `json:"version" yaml:"version" toml:"version"`
+	Files   []*FileOutline `json:"files" yaml:"files" toml:"files"`
+}
+
+// FileOutline 单个文件的大纲
+type FileOutline struct {
+	Path     string            `json:"path" yaml:"path" toml:"path"`
+	Headers  []*OutlineNode    `json:"headers" yaml:"headers" toml:"headers"`    // 嵌套的标题树 (受 MinLevel/MaxLevel 限制)
+	Sections []*OutlineSection `json:"sections" yaml:"sections" toml:"sections"` // 按 H1 分割的章节
+}
+
+// OutlineNode 标题树中的节点
+type OutlineNode struct {
+	Level    int            `json:"level" yaml:"level" toml:"level"`
+	Text     string         `json:"text" yaml:"text" toml:"text"`
+	Anchor   string         `json:"anchor" yaml:"anchor" toml:"anchor"`
+	Line     int            `json:"line" yaml:"line" toml:"line"`
+	EndLine  int            `json:"end_line" yaml:"end_line" toml:"end_line"`
+	Children []*OutlineNode `json:"children,omitempty" yaml:"children,omitempty" toml:"children,omitempty"`
+}
+
+// OutlineSection 章节 (一个 H1 及其后续内容) 的位置信息
+type OutlineSection struct {
+	Title   string `json:"title" yaml:"title" toml:"title"`
+	Anchor  string `json:"anchor" yaml:"anchor" toml:"anchor"`
+	Line    int    `json:"line" yaml:"line" toml:"line"`
+	EndLine int    `json:"end_line" yaml:"end_line" toml:"end_line"`
+}
+
+// NewOutline 创建当前版本的大纲对象
+func NewOutline() *Outline {
+	return &Outline{Version: OutlineVersion, Files: []*FileOutline{}}
+}
+
+// BuildTree 将扁平的标题列表按层级嵌套为树
+// 跳级的标题 (如 H2 后直接出现 H4) 挂在最近的更高层级标题下
+func BuildTree(headers []*Header) []*OutlineNode {
+	roots := []*OutlineNode{}
+	var stack []*OutlineNode
+
+	for _, h := range headers {
+		node := &OutlineNode{
+			Level:   h.Level,
+			Text:    h.Text,
+			Anchor:  h.AnchorLink,
+			Line:    h.Line,
+			EndLine: h.EndLine,
+		}
+
+		// 弹出层级不低于当前标题的节点，栈顶即为父节点
+		for len(stack) > 0 && stack[len(stack)-1].Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+		}
+		stack = append(stack, node)
+	}
+
+	return roots
+}
+
+// Outline 解析文档内容，返回文件大纲
+// 文档 frontmatter 中的层级设置会覆盖 Options；toc: false 只影响 TOC 的写入，大纲仍然输出
+func (t *TOC) Outline(path string, content []byte) (*FileOutline, error) {
+	doc, err := ParseFrontmatter(content)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := doc.Apply(t.options)
+	if err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	d := New(opts)
+
+	headers, err := d.parser.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	// 章节范围始终包含子标题，不受 LineExclusive 显示选项影响
+	inclusive := opts
+	inclusive.LineExclusive = false
+	allHeaders, err := NewParser(inclusive).ParseAllHeaders(content)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := []*OutlineSection{}
+	for _, s := range SplitSections(allHeaders) {
+		sections = append(sections, &OutlineSection{
+			Title:   s.Title.Text,
+			Anchor:  s.Title.AnchorLink,
+			Line:    s.Title.Line,
+			EndLine: s.Title.EndLine,
+		})
+	}
+
+	return &FileOutline{
+		Path:     path,
+		Headers:  BuildTree(headers),
+		Sections: sections,
+	}, nil
+}
@@ -0,0 +1,162 @@
+package mdtoc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuildTree(t *testing.T) {
+	headers := []*Header{
+		{Level: 1, Text: "Title"},
+		{Level: 2, Text: "A"},
+		{Level: 4, Text: "A.deep"}, // 跳级
+		{Level: 3, Text: "A.1"},
+		{Level: 2, Text: "B"},
+		{Level: 1, Text: "Appendix"},
+	}
+
+	roots := BuildTree(headers)
+	if len(roots) != 2 {
+		t.Fatalf("BuildTree() got %d roots, want 2", len(roots))
+	}
+
+	title := roots[0]
+	if len(title.Children) != 2 || title.Children[0].Text != "A" || title.Children[1].Text != "B" {
+		t.Fatalf("Title children = %+v, want [A B]", title.Children)
+	}
+
+	a := title.Children[0]
+	if len(a.Children) != 2 || a.Children[0].Text != "A.deep" || a.Children[1].Text != "A.1" {
+		t.Errorf("A children = %+v, want [A.deep A.1]", a.Children)
+	}
+
+	if roots[1].Text != "Appendix" || len(roots[1].Children) != 0 {
+		t.Errorf("roots[1] = %+v, want Appendix without children", roots[1])
+	}
+}
+
+func TestBuildTree_NoH1(t *testing.T) {
+	// 没有 H1 时，最高层级的标题作为根节点
+	roots := BuildTree([]*Header{
+		{Level: 2, Text: "A"},
+		{Level: 3, Text: "A.1"},
+		{Level: 2, Text: "B"},
+	})
+	if len(roots) != 2 || len(roots[0].Children) != 1 {
+		t.Errorf("BuildTree() = %+v, want [A [A.1]] [B]", roots)
+	}
+}
+
+func TestTOC_Outline_JSON(t *testing.T) {
+	content := `---
+toc: false
+toc_max_level: 2
+---
+# Guide
+
+## Install
+
+### Linux
+
+# API
+
+## Types
+`
+	toc := New(Options{MinLevel: 1, MaxLevel: 3})
+	file, err := toc.Outline("docs/guide.md", []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outline := NewOutline()
+	outline.Files = append(outline.Files, file)
+
+	got, err := json.Marshal(outline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// frontmatter 的层级设置生效 (### Linux 被过滤)，toc: false 不影响大纲输出
+	want := `{"version":1,"files":[{"path":"docs/guide.md","headers":[` +
+		`{"level":1,"text":"Guide","anchor":"guide","line":5,"end_line":10,"children":[` +
+		`{"level":2,"text":"Install","anchor":"install","line":7,"end_line":10}]},` +
+		`{"level":1,"text":"API","anchor":"api","line":11,"end_line":13,"children":[` +
+		`{"level":2,"text":"Types","anchor":"types","line":13,"end_line":13}]}],` +
+		`"sections":[` +
+		`{"title":"Guide","anchor":"guide","line":5,"end_line":10},` +
+		`{"title":"API","anchor":"api","line":11,"end_line":13}]}]}`
+	if string(got) != want {
+		t.Errorf("json =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestTOC_Outline_LineExclusive 测试 LineExclusive 只影响标题的行号范围，不影响章节划分
+func TestTOC_Outline_LineExclusive(t *testing.T) {
+	content := "# Guide\n\n## Install\n\ntext\n\n# API\n\n## Types\n"
+	toc := New(Options{MinLevel: 1, MaxLevel: 3, LineExclusive: true})
+	file, err := toc.Outline("guide.md", []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := file.Headers[0].EndLine; got != 2 {
+		t.Errorf("headers[0].EndLine = %d, want 2 (exclusive)", got)
+	}
+	if len(file.Sections) != 2 {
+		t.Fatalf("len(Sections) = %d, want 2", len(file.Sections))
+	}
+	if got := file.Sections[0].EndLine; got != 6 {
+		t.Errorf("sections[0].EndLine = %d, want 6", got)
+	}
+	if got := file.Sections[1].EndLine; got != 9 {
+		t.Errorf("sections[1].EndLine = %d, want 9", got)
+	}
+}
+
+// TestOutline_YAMLAndTOML 测试 YAML/TOML 输出与 JSON 使用相同的字段名，且可无损还原
+func TestOutline_YAMLAndTOML(t *testing.T) {
+	content := "# Guide\n\n## Install\n\n### Linux\n\n## Usage\n"
+	file, err := New(Options{MinLevel: 1, MaxLevel: 3}).Outline("guide.md", []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outline := NewOutline()
+	outline.Files = append(outline.Files, file)
+
+	codecs := []struct {
+		name      string
+		marshal   func(any) ([]byte, error)
+		unmarshal func([]byte, any) error
+		contains  []string
+	}{
+		{"yaml", yaml.Marshal, yaml.Unmarshal, []string{"version: 1", "end_line: 6", "children:"}},
+		{"toml", toml.Marshal, toml.Unmarshal, []string{"version = 1", "end_line = 6", "[[files.headers.children]]"}},
+	}
+
+	for _, c := range codecs {
+		t.Run(c.name, func(t *testing.T) {
+			out, err := c.marshal(outline)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range c.contains {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("output should contain %q, got:\n%s", want, out)
+				}
+			}
+
+			var got Outline
+			if err := c.unmarshal(out, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(&got, outline) {
+				t.Errorf("round trip mismatch:\n%s", out)
+			}
+		})
+	}
+}
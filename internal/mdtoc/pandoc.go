@@ -0,0 +1,47 @@
+package mdtoc
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PandocSlugger 生成与 Pandoc auto_identifiers 扩展一致的标题 id
+type PandocSlugger struct {
+	used map[string]bool // 已使用的 id
+}
+
+// NewPandocSlugger 创建 Pandoc 风格的锚点生成器
+func NewPandocSlugger() *PandocSlugger {
+	return &PandocSlugger{used: make(map[string]bool)}
+}
+
+// Generate 生成 anchor link
+// 规则 (参考 Pandoc 手册 Extension: auto_identifiers):
+// 1. 移除 HTML 标签和 Markdown 标记，转小写
+// 2. 保留字母、数字、_、- 和 .，其余字符移除
+// 3. 按空白分词后以 - 连接 (不合并已有的连字符)
+// 4. 移除第一个字母之前的所有字符 (id 不能以数字或标点开头)，结果为空时为 section
+// 5. 与已使用的 id 重复时依次尝试 -1, -2, ...
+func (g *PandocSlugger) Generate(text string) string {
+	anchor := strings.ToLower(removeEmphasis(removeHTMLTags(text)))
+
+	anchor = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsSpace(r) || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return -1
+	}, anchor)
+	anchor = strings.Join(strings.Fields(anchor), "-")
+	anchor = strings.TrimLeftFunc(anchor, func(r rune) bool { return !unicode.IsLetter(r) })
+	if anchor == "" {
+		anchor = "section"
+	}
+
+	unique := anchor
+	for i := 1; g.used[unique]; i++ {
+		unique = anchor + "-" + strconv.Itoa(i)
+	}
+	g.used[unique] = true
+	return unique
+}
@@ -2,6 +2,8 @@ package mdtoc
 
 import (
 	"bytes"
+	"fmt"
+	"sync"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
@@ -9,23 +11,37 @@ import (
 	"github.com/yuin/goldmark/text"
 )
 
+// markdown 共享的 goldmark 实例
+// goldmark 解析器可并发使用，所有 Parser 复用同一实例，避免逐文件重复构建
+var markdown = sync.OnceValue(func() goldmark.Markdown {
+	return goldmark.New(
+		goldmark.WithParserOptions(
+			parser.WithAttribute(), // 解析标题属性 ({#id .class})，显式指定的 id 作为锚点
+		),
+	)
+})
+
+// markdownHeadingIDs 由 goldmark 为标题自动生成 id 的共享实例，用于 HeadingIDSlugger
+var markdownHeadingIDs = sync.OnceValue(func() goldmark.Markdown {
+	return goldmark.New(
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(), // 自动生成标题 ID
+			parser.WithAttribute(),
+		),
+	)
+})
+
 // Parser 解析 Markdown 文档并提取标题
+// Parser 不保存解析过程中的状态，可在多个 goroutine 中并发使用
 type Parser struct {
 	md      goldmark.Markdown
-	anchor  *AnchorGenerator
 	options Options
 }
 
 // NewParser 创建新的解析器
 func NewParser(opts Options) *Parser {
-	md := goldmark.New(
-		goldmark.WithParserOptions(
-			parser.WithAutoHeadingID(), // 自动生成标题 ID
-		),
-	)
 	return &Parser{
-		md:      md,
-		anchor:  NewAnchorGenerator(),
+		md:      markdown(),
 		options: opts,
 	}
 }
@@ -44,8 +60,16 @@ func (p *Parser) ParseAllHeaders(content []byte) ([]*Header, error) {
 // parseHeaders 解析标题的内部实现
 // filterLevel 控制是否按 MinLevel/MaxLevel 过滤标题
 func (p *Parser) parseHeaders(content []byte, filterLevel bool) ([]*Header, error) {
-	// 重置锚点生成器
-	p.anchor.Reset()
+	// 每次解析使用独立的锚点生成器，重复标题计数只在单个文档内有效
+	anchors, err := NewSlugger(p.options.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	// HTML 文档按标签提取标题
+	if p.options.HTMLInput {
+		return p.parseHTMLHeaders(content, anchors, filterLevel), nil
+	}
 
 	// 检测并跳过 frontmatter
 	lines := bytes.Split(content, []byte("\n"))
@@ -63,32 +87,26 @@ func (p *Parser) parseHeaders(content []byte, filterLevel bool) ([]*Header, erro
 	totalLines := countLines(content)
 
 	// 解析为 AST
-	reader := text.NewReader(parseContent)
-	doc := p.md.Parser().Parse(reader)
-
-	var headers []*Header
-
-	// 遍历 AST 提取标题
-	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		if !entering {
-			return ast.WalkContinue, nil
-		}
-
-		heading, ok := n.(*ast.Heading)
-		if !ok {
-			return ast.WalkContinue, nil
-		}
+	doc, err := p.parseAST(parseContent, anchors)
+	if err != nil {
+		return nil, err
+	}
 
-		// 检查层级范围（仅在 filterLevel 为 true 时）
-		if filterLevel && (heading.Level < p.options.MinLevel || heading.Level > p.options.MaxLevel) {
-			return ast.WalkSkipChildren, nil
-		}
+	headings, explicit, err := collectHeadings(doc)
+	if err != nil {
+		return nil, err
+	}
 
-		// 提取标题文本
+	headers := make([]*Header, 0, len(headings))
+	for _, heading := range headings {
+		// 提取标题文本 (属性块已由 goldmark 移除)
 		text := extractText(parseContent, heading)
 
-		// 生成 anchor link
-		anchor := p.anchor.Generate(text)
+		// 生成 anchor link，标题已有 id (显式指定或由 goldmark 生成) 时直接使用
+		anchor := headingID(heading)
+		if anchor == "" {
+			anchor = generateAnchor(anchors, text, explicit)
+		}
 
 		// 获取行号（需要加上 frontmatter 的偏移）
 		line := getNodeLine(heading, lineMap) + lineOffset
@@ -99,14 +117,79 @@ func (p *Parser) parseHeaders(content []byte, filterLevel bool) ([]*Header, erro
 			AnchorLink: anchor,
 			Line:       line,
 		})
+	}
+
+	return p.finishHeaders(headers, totalLines, filterLevel), nil
+}
+
+// parseAST 将 Markdown 解析为 AST
+// 使用 HeadingIDSlugger 时由 goldmark 在解析时为没有显式 id 的标题生成 id
+func (p *Parser) parseAST(source []byte, anchors Slugger) (doc ast.Node, err error) {
+	headingIDs, ok := anchors.(HeadingIDSlugger)
+	if !ok {
+		return p.md.Parser().Parse(text.NewReader(source)), nil
+	}
+
+	// goldmark 按文档顺序生成 id，只能避开已出现的显式 id；
+	// 先解析一遍登记全部显式 id，保证生成的 id 不与后文的 {#id} 重复
+	ids := headingIDs.IDs()
+	_, explicit, err := collectHeadings(p.md.Parser().Parse(text.NewReader(source)))
+	if err != nil {
+		return nil, err
+	}
+	for id := range explicit {
+		ids.Put([]byte(id))
+	}
+
+	// goldmark 登记显式 id 时假定其为字符串，{id=1} 等非字符串的 id 会导致 panic
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("解析标题 id 失败: %v", r)
+		}
+	}()
+	ctx := parser.NewContext(parser.WithIDs(ids))
+	return markdownHeadingIDs().Parser().Parse(text.NewReader(source), parser.WithContext(ctx)), nil
+}
+
+// collectHeadings 遍历 AST 收集标题，同时记录显式指定的 id ({#id})
+func collectHeadings(doc ast.Node) ([]*ast.Heading, map[string]bool, error) {
+	var headings []*ast.Heading
+	explicit := make(map[string]bool)
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		heading, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		if id := headingID(heading); id != "" {
+			explicit[id] = true
+		}
+		headings = append(headings, heading)
 
 		return ast.WalkSkipChildren, nil
 	})
+	return headings, explicit, err
+}
 
-	// 计算每个标题的结束行
-	calculateEndLines(headers, totalLines)
+// finishHeaders 计算结束行并按层级过滤
+// 结束行基于完整的标题列表计算，不受层级过滤影响
+func (p *Parser) finishHeaders(headers []*Header, totalLines int, filterLevel bool) []*Header {
+	calculateEndLines(headers, totalLines, p.options.LineExclusive)
+	if !filterLevel {
+		return headers
+	}
 
-	return headers, err
+	filtered := headers[:0]
+	for _, h := range headers {
+		if h.Level >= p.options.MinLevel && h.Level <= p.options.MaxLevel {
+			filtered = append(filtered, h)
+		}
+	}
+	return filtered
 }
 
 // buildLineMap 构建 byte offset 到行号的映射
@@ -154,14 +237,14 @@ func getNodeLine(n ast.Node, lineMap []int) int {
 }
 
 // calculateEndLines 计算每个标题的结束行
-// 规则：标题的结束行是下一个同级或更高级标题的前一行
-// 这样父级标题会包含其所有子级内容
-func calculateEndLines(headers []*Header, totalLines int) {
+// 规则：标题的结束行是下一个同级或更高级标题的前一行，这样父级标题会包含其所有子级内容；
+// exclusive 为 true 时结束于下一个任意级别标题 (即第一个子标题) 的前一行，只包含标题自身的内容
+func calculateEndLines(headers []*Header, totalLines int, exclusive bool) {
 	for i, h := range headers {
 		// 查找下一个同级或更高级的标题
 		endLine := totalLines
 		for j := i + 1; j < len(headers); j++ {
-			if headers[j].Level <= h.Level {
+			if exclusive || headers[j].Level <= h.Level {
 				// 找到同级或更高级标题，结束行是其前一行
 				endLine = headers[j].Line - 1
 				break
@@ -229,3 +312,20 @@ func writeNodeText(src []byte, buf *bytes.Buffer, n ast.Node) {
 		}
 	}
 }
+
+// generateAnchor 按锚点规则生成 anchor link
+// 显式指定的 id 预先占用，生成的锚点与其重复时继续生成下一个 (如 title-1)
+func generateAnchor(anchors Slugger, text string, explicit map[string]bool) string {
+	anchor := anchors.Generate(text)
+	for explicit[anchor] {
+		anchor = anchors.Generate(text)
+	}
+	return anchor
+}
+
+// headingID 返回标题的 id 属性，未设置或不是字符串 (如 {id=1}) 时返回空
+func headingID(heading *ast.Heading) string {
+	v, _ := heading.AttributeString("id")
+	b, _ := v.([]byte)
+	return string(b)
+}
@@ -1,6 +1,8 @@
 package mdtoc
 
 import (
+	"slices"
+	"sync"
 	"testing"
 )
 
@@ -174,6 +176,59 @@ More content...
 	}
 }
 
+func TestParser_LineExclusive(t *testing.T) {
+	content := `# Title
+Intro
+
+## Section 1
+Content...
+### Detail
+Detail content...
+
+## Section 2
+More content...
+`
+	tests := []struct {
+		name     string
+		opts     Options
+		endLines map[string]int
+	}{
+		{
+			name:     "inclusive",
+			opts:     Options{MinLevel: 1, MaxLevel: 3},
+			endLines: map[string]int{"Title": 10, "Section 1": 8, "Detail": 8, "Section 2": 10},
+		},
+		{
+			name:     "exclusive",
+			opts:     Options{MinLevel: 1, MaxLevel: 3, LineExclusive: true},
+			endLines: map[string]int{"Title": 3, "Section 1": 5, "Detail": 8, "Section 2": 10},
+		},
+		{
+			// 结束行基于完整的标题列表计算，被过滤的子标题同样截断父标题
+			name:     "exclusive with filtered children",
+			opts:     Options{MinLevel: 2, MaxLevel: 2, LineExclusive: true},
+			endLines: map[string]int{"Section 1": 5, "Section 2": 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewParser(tt.opts).Parse([]byte(content))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(got) != len(tt.endLines) {
+				t.Fatalf("Parse() returned %d headers, want %d", len(got), len(tt.endLines))
+			}
+			for _, h := range got {
+				if h.EndLine != tt.endLines[h.Text] {
+					t.Errorf("%s.EndLine = %d, want %d", h.Text, h.EndLine, tt.endLines[h.Text])
+				}
+			}
+		})
+	}
+}
+
 func TestCountLines(t *testing.T) {
 	tests := []struct {
 		content  string
@@ -586,3 +641,106 @@ Content
 		})
 	}
 }
+
+// TestParser_Concurrent 测试同一 Parser 在多个 goroutine 中并发解析
+// 重复标题的锚点后缀只在单个文档内计数，互不干扰
+func TestParser_Concurrent(t *testing.T) {
+	content := []byte("# Title\n\n## Intro\n\n## Intro\n\n### 安装\n")
+	want := []string{"title", "intro", "intro-1", "安装"}
+
+	p := NewParser(Options{MinLevel: 1, MaxLevel: 6})
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Go(func() {
+			for range 20 {
+				headers, err := p.Parse(content)
+				if err != nil {
+					t.Errorf("Parse() error = %v", err)
+					return
+				}
+				if len(headers) != len(want) {
+					t.Errorf("Parse() got %d headers, want %d", len(headers), len(want))
+					return
+				}
+				for i, h := range headers {
+					if h.AnchorLink != want[i] {
+						t.Errorf("headers[%d].AnchorLink = %q, want %q", i, h.AnchorLink, want[i])
+					}
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// TestParser_ExplicitID 测试显式指定的标题 id ({#id})
+// 属性块不出现在标题文本中，显式 id 预先占用，生成的锚点不会与其重复
+func TestParser_ExplicitID(t *testing.T) {
+	content := "# Guide\n\n## Install\n\n## Installation {#install}\n\n## Usage {.wide}\n\n" +
+		"## Setup {#install-1 .note}\n\nFAQ {#faq}\n---\n\n## Count {id=1}\n"
+
+	tests := []struct {
+		slug     string
+		expected []string
+	}{
+		{SlugGitHub, []string{"guide", "install-2", "install", "usage", "install-1", "faq", "count"}},
+		{SlugVitePress, []string{"guide", "install-2", "install", "usage", "install-1", "faq", "count"}},
+		{SlugPythonMarkdown, []string{"guide", "install_1", "install", "usage", "install-1", "faq", "count"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			opts := Options{MinLevel: 1, MaxLevel: 6, Slug: tt.slug}
+			headers, err := NewParser(opts).Parse([]byte(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var texts, anchors []string
+			for _, h := range headers {
+				texts = append(texts, h.Text)
+				anchors = append(anchors, h.AnchorLink)
+			}
+			if want := []string{"Guide", "Install", "Installation", "Usage", "Setup", "FAQ", "Count"}; !slices.Equal(texts, want) {
+				t.Errorf("texts = %q, want %q", texts, want)
+			}
+			if !slices.Equal(anchors, tt.expected) {
+				t.Errorf("anchors = %q, want %q", anchors, tt.expected)
+			}
+		})
+	}
+
+	// Hugo 规则由 goldmark 按文档顺序生成 id，后文的显式 id 同样预先占用
+	for _, slug := range []string{SlugHugo, SlugHugoASCII, SlugHugoBlackfriday} {
+		opts := Options{MinLevel: 1, MaxLevel: 6, Slug: slug}
+		headers, err := NewParser(opts).Parse([]byte("## Install\n## Setup {#install}\n## Installation\n## Install\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var anchors []string
+		for _, h := range headers {
+			anchors = append(anchors, h.AnchorLink)
+		}
+		if want := []string{"install-1", "install", "installation", "install-2"}; !slices.Equal(anchors, want) {
+			t.Errorf("%s anchors = %q, want %q", slug, anchors, want)
+		}
+	}
+
+	opts := Options{MinLevel: 1, MaxLevel: 6, Slug: SlugHugo}
+
+	// goldmark 无法登记非字符串的 id，返回错误而不是 panic
+	if _, err := NewParser(opts).Parse([]byte("## Count {id=1}\n")); err == nil {
+		t.Error("Parse() with non-string id should return error")
+	}
+
+	// HTML 文档中的 id 属性同样预先占用
+	opts = Options{MinLevel: 1, MaxLevel: 6, HTMLInput: true}
+	headers, err := NewParser(opts).Parse([]byte("<h2>Install</h2>\n<h2 id=\"install\">Setup</h2>\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if headers[0].AnchorLink != "install-1" || headers[1].AnchorLink != "install" {
+		t.Errorf("html anchors = %q, %q, want install-1, install", headers[0].AnchorLink, headers[1].AnchorLink)
+	}
+}
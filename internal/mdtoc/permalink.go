@@ -0,0 +1,72 @@
+package mdtoc
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PermalinkTemplates 各源码托管平台指向行号范围的 URL 模板
+//
+// 模板占位符：
+//   - {base}: 仓库网页地址，如 https://github.com/owner/repo
+//   - {ref}: 分支、标签或提交
+//   - {path}: 文件在仓库中的相对路径
+//   - {abs}: 文件的绝对路径
+//   - {start} / {end}: 起止行号
+var PermalinkTemplates = map[string]string{
+	"github": "{base}/blob/{ref}/{path}#L{start}-L{end}",
+	"gitlab": "{base}/-/blob/{ref}/{path}#L{start}-{end}",
+	"gitea":  "{base}/src/{ref}/{path}#L{start}-L{end}",
+	"file":   "file://{abs}#L{start}-L{end}",
+}
+
+// PermalinkTemplate 返回平台名称对应的 URL 模板
+// 包含 { 的参数视为自定义模板原样返回
+func PermalinkTemplate(name string) (string, error) {
+	if tmpl, ok := PermalinkTemplates[name]; ok {
+		return tmpl, nil
+	}
+	if strings.Contains(name, "{") {
+		return name, nil
+	}
+	return "", fmt.Errorf("不支持的永久链接平台 %q (可选: github, gitlab, gitea, file 或包含 {start} 等占位符的 URL 模板)", name)
+}
+
+// Permalink 文件在源码托管平台上的行号范围链接
+type Permalink struct {
+	Template string // URL 模板
+	Base     string // 仓库网页地址 (末尾不带 /)
+	Ref      string // 分支、标签或提交
+	Path     string // 文件在仓库中的相对路径 (/ 分隔)
+	AbsPath  string // 文件的绝对路径 (/ 分隔)
+}
+
+// NeedsRepo 检查模板是否引用了仓库地址、引用或仓库内路径
+func (p *Permalink) NeedsRepo() bool {
+	return strings.Contains(p.Template, "{base}") ||
+		strings.Contains(p.Template, "{ref}") ||
+		strings.Contains(p.Template, "{path}")
+}
+
+// URL 返回指向 start-end 行的链接，路径按段转义
+func (p *Permalink) URL(start, end int) string {
+	return strings.NewReplacer(
+		"{base}", strings.TrimSuffix(p.Base, "/"),
+		"{ref}", escapePath(p.Ref),
+		"{path}", escapePath(p.Path),
+		"{abs}", escapePath(p.AbsPath),
+		"{start}", strconv.Itoa(start),
+		"{end}", strconv.Itoa(end),
+	).Replace(p.Template)
+}
+
+// escapePath 按段转义路径，保留 / 分隔符
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,60 @@
+package mdtoc
+
+import (
+	"testing"
+)
+
+func TestPermalink_URL(t *testing.T) {
+	tests := []struct {
+		forge    string
+		expected string
+	}{
+		{"github", "https://github.com/owner/repo/blob/feat/x/docs/%E8%AE%BE%E8%AE%A1%20v2.md#L10-L20"},
+		{"gitlab", "https://github.com/owner/repo/-/blob/feat/x/docs/%E8%AE%BE%E8%AE%A1%20v2.md#L10-20"},
+		{"gitea", "https://github.com/owner/repo/src/feat/x/docs/%E8%AE%BE%E8%AE%A1%20v2.md#L10-L20"},
+		{"file", "file:///home/me/repo/docs/%E8%AE%BE%E8%AE%A1%20v2.md#L10-L20"},
+		{"{base}/raw/{ref}/{path}?start={start}", "https://github.com/owner/repo/raw/feat/x/docs/%E8%AE%BE%E8%AE%A1%20v2.md?start=10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.forge, func(t *testing.T) {
+			tmpl, err := PermalinkTemplate(tt.forge)
+			if err != nil {
+				t.Fatalf("PermalinkTemplate(%q) error = %v", tt.forge, err)
+			}
+			p := &Permalink{
+				Template: tmpl,
+				Base:     "https://github.com/owner/repo/",
+				Ref:      "feat/x",
+				Path:     "docs/设计 v2.md",
+				AbsPath:  "/home/me/repo/docs/设计 v2.md",
+			}
+			if got := p.URL(10, 20); got != tt.expected {
+				t.Errorf("URL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := PermalinkTemplate("bitbucket"); err == nil {
+		t.Error("PermalinkTemplate(bitbucket) should fail")
+	}
+}
+
+func TestGenerator_Permalink(t *testing.T) {
+	headers := []*Header{
+		{Level: 2, Text: "Install", AnchorLink: "install", Line: 3, EndLine: 8},
+	}
+	p := &Permalink{Template: PermalinkTemplates["github"], Base: "https://github.com/o/r", Ref: "main", Path: "a.md"}
+
+	got := mustGenerate(t, NewGenerator(Options{MinLevel: 2, MaxLevel: 3, ShowAnchor: true, LineNumber: true, Permalink: p}), headers)
+	expected := "- [Install](#install) [`:3+6`](https://github.com/o/r/blob/main/a.md#L3-L8)"
+	if got != expected {
+		t.Errorf("Generate() = %q, want %q", got, expected)
+	}
+
+	// 未启用行号时不生成链接
+	got = mustGenerate(t, NewGenerator(Options{MinLevel: 2, MaxLevel: 3, ShowAnchor: true, Permalink: p}), headers)
+	if got != "- [Install](#install)" {
+		t.Errorf("Generate() without line numbers = %q", got)
+	}
+}
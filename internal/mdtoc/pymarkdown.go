@@ -0,0 +1,60 @@
+package mdtoc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// pymdPunctRe 匹配 Python 正则 [^\w\s-] (ASCII 化之后)
+	pymdPunctRe = regexp.MustCompile(`[^\w\s-]`)
+	// pymdSeparatorRe 连续的空白和连字符
+	pymdSeparatorRe = regexp.MustCompile(`[-\s]+`)
+	// pymdCountRe 以 _数字 结尾的 id
+	pymdCountRe = regexp.MustCompile(`^(.*)_([0-9]+)$`)
+)
+
+// PythonMarkdownSlugger 生成与 Python-Markdown toc 扩展 (MkDocs 默认) 一致的标题 id
+type PythonMarkdownSlugger struct {
+	used map[string]bool // 已使用的 id
+}
+
+// NewPythonMarkdownSlugger 创建 Python-Markdown 风格的锚点生成器
+func NewPythonMarkdownSlugger() *PythonMarkdownSlugger {
+	return &PythonMarkdownSlugger{used: make(map[string]bool)}
+}
+
+// Generate 生成 anchor link
+// 规则 (参考 markdown.extensions.toc 的 slugify 和 unique):
+// 1. 移除 HTML 标签和 Markdown 标记
+// 2. NFKD 分解后丢弃非 ASCII 字符 (žlutý -> zluty，中文标题被整体丢弃)
+// 3. 移除字母、数字、_、空白和 - 以外的字符，去除首尾空白后转小写
+// 4. 连续的空白和连字符替换为一个 -
+// 5. 重复或为空时添加 _1 后缀，已有 _数字 后缀时递增 (title、title_1、title_2)
+func (g *PythonMarkdownSlugger) Generate(text string) string {
+	anchor := removeEmphasis(removeHTMLTags(text))
+
+	anchor = strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII {
+			return -1
+		}
+		return r
+	}, norm.NFKD.String(anchor))
+	anchor = strings.ToLower(strings.TrimSpace(pymdPunctRe.ReplaceAllString(anchor, "")))
+	anchor = pymdSeparatorRe.ReplaceAllString(anchor, "-")
+
+	for g.used[anchor] || anchor == "" {
+		if m := pymdCountRe.FindStringSubmatch(anchor); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			anchor = m[1] + "_" + strconv.Itoa(n+1)
+		} else {
+			anchor += "_1"
+		}
+	}
+	g.used[anchor] = true
+	return anchor
+}
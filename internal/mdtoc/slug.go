@@ -0,0 +1,153 @@
+package mdtoc
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Slugger 将标题文本转换为锚点
+// 实现负责处理同一文档内的重复标题，每个文档使用新的实例
+type Slugger interface {
+	Generate(text string) string
+}
+
+// 锚点生成规则
+const (
+	SlugGitHub          = "github"           // GitHub (默认)
+	SlugGitLab          = "gitlab"           // GitLab
+	SlugVitePress       = "vitepress"        // VitePress (markdown-it-anchor)
+	SlugHugo            = "hugo"             // Hugo autoHeadingIDType: github (Hugo 默认)
+	SlugHugoASCII       = "hugo-ascii"       // Hugo autoHeadingIDType: github-ascii
+	SlugHugoBlackfriday = "hugo-blackfriday" // Hugo autoHeadingIDType: blackfriday
+	SlugPandoc          = "pandoc"           // Pandoc auto_identifiers
+	SlugPythonMarkdown  = "python-markdown"  // Python-Markdown toc 扩展 (MkDocs)
+)
+
+// Sluggers 支持的锚点生成规则，值为创建新实例的函数
+var Sluggers = map[string]func() Slugger{
+	SlugGitHub:          func() Slugger { return NewAnchorGenerator() },
+	SlugGitLab:          func() Slugger { return NewGitLabSlugger() },
+	SlugVitePress:       func() Slugger { return NewVitePressSlugger() },
+	SlugHugo:            func() Slugger { return NewHugoSlugger(hugoIDGitHub) },
+	SlugHugoASCII:       func() Slugger { return NewHugoSlugger(hugoIDGitHubASCII) },
+	SlugHugoBlackfriday: func() Slugger { return NewHugoSlugger(hugoIDBlackfriday) },
+	SlugPandoc:          func() Slugger { return NewPandocSlugger() },
+	SlugPythonMarkdown:  func() Slugger { return NewPythonMarkdownSlugger() },
+}
+
+// SlugNames 返回按名称排序的锚点生成规则
+func SlugNames() []string {
+	return slices.Sorted(maps.Keys(Sluggers))
+}
+
+// NewSlugger 按名称创建锚点生成器，空值为 GitHub 规则
+func NewSlugger(name string) (Slugger, error) {
+	if name == "" {
+		name = SlugGitHub
+	}
+	newSlugger, ok := Sluggers[name]
+	if !ok {
+		return nil, fmt.Errorf("不支持的锚点规则 %q (可选: %s)", name, strings.Join(SlugNames(), ", "))
+	}
+	return newSlugger(), nil
+}
+
+// gitlabDigitsRe 匹配只包含数字的锚点
+var gitlabDigitsRe = regexp.MustCompile(`^[0-9]+$`)
+
+// GitLabSlugger 生成 GitLab 风格的锚点
+type GitLabSlugger struct {
+	counter map[string]int // 重复标题计数器
+}
+
+// NewGitLabSlugger 创建 GitLab 风格的锚点生成器
+func NewGitLabSlugger() *GitLabSlugger {
+	return &GitLabSlugger{counter: make(map[string]int)}
+}
+
+// Generate 生成 anchor link
+// 规则 (参考 GitLab Markdown 文档):
+// 1. 移除 HTML 标签和 Markdown 标记后去除首尾空白，转小写
+// 2. 保留 Unicode 字母、组合标记、数字、连接符 (如 _)、连字符和空格
+// 3. 空格转连字符，合并多个连字符 (不去除首尾的连字符)
+// 4. 只包含数字的锚点添加 anchor- 前缀
+// 5. 处理重复标题 (添加 -1, -2, ...)
+func (g *GitLabSlugger) Generate(text string) string {
+	anchor := removeEmphasis(removeHTMLTags(text))
+	anchor = strings.ToLower(strings.TrimSpace(anchor))
+
+	anchor = strings.Map(func(r rune) rune {
+		if unicode.In(r, unicode.L, unicode.M, unicode.Nd, unicode.Pc) || r == '-' || r == ' ' {
+			return r
+		}
+		return -1
+	}, anchor)
+
+	anchor = mergeHyphens(strings.ReplaceAll(anchor, " ", "-"))
+	if gitlabDigitsRe.MatchString(anchor) {
+		anchor = "anchor-" + anchor
+	}
+
+	count := g.counter[anchor]
+	g.counter[anchor] = count + 1
+	if count > 0 {
+		return anchor + "-" + strconv.Itoa(count)
+	}
+	return anchor
+}
+
+var (
+	// vitepressSpecialRe 替换为连字符的空白和 ASCII 标点 (含中英文引号)
+	// JavaScript 的 \s 包含 Unicode 空白，RE2 的 \s 只匹配 ASCII 空白，需要单独列出
+	vitepressSpecialRe = regexp.MustCompile(`[\s\v\p{Zs}\x{2028}\x{2029}\x{FEFF}~` + "`" + `!@#$%^&*()\-_+=\[\]{}|\\;:"'“”‘’<>,.?/]+`)
+	// vitepressCombiningRe 分解后的组合变音符号 (U+0300-U+036F)
+	vitepressCombiningRe = regexp.MustCompile(`[\x{0300}-\x{036F}]`)
+	// vitepressControlRe 控制字符
+	vitepressControlRe = regexp.MustCompile(`[\x00-\x1F]`)
+)
+
+// VitePressSlugger 生成 VitePress 风格的锚点
+// 与 VitePress 默认的 slugify (@mdit-vue/shared) 和 markdown-it-anchor 的去重规则一致
+type VitePressSlugger struct {
+	used map[string]bool // 已使用的锚点
+}
+
+// NewVitePressSlugger 创建 VitePress 风格的锚点生成器
+func NewVitePressSlugger() *VitePressSlugger {
+	return &VitePressSlugger{used: make(map[string]bool)}
+}
+
+// Generate 生成 anchor link
+// 规则 (参考 @mdit-vue/shared slugify):
+// 1. 标题文本只保留文字和行内代码 (移除图片、HTML 标签和强调符号)
+// 2. NFKD 分解后移除变音符号和控制字符 (é -> e)
+// 3. 空白和 ASCII 标点 (包括 _ 和 -) 替换为连字符，合并连续的连字符并去除首尾的连字符
+// 4. 数字开头时添加 _ 前缀，最后转小写；中日韩文字和全角标点保持不变
+// 5. 与已使用的锚点重复时依次尝试 -1, -2, ... (标题 "a"、"a"、"a-1" 生成 a、a-1、a-1-1)
+func (g *VitePressSlugger) Generate(text string) string {
+	anchor := removeEmphasis(removeHTMLTags(imgRe.ReplaceAllString(text, "")))
+
+	anchor = norm.NFKD.String(anchor)
+	anchor = vitepressCombiningRe.ReplaceAllString(anchor, "")
+	anchor = vitepressControlRe.ReplaceAllString(anchor, "")
+	anchor = vitepressSpecialRe.ReplaceAllString(anchor, "-")
+	anchor = strings.Trim(mergeHyphens(anchor), "-")
+	if anchor != "" && anchor[0] >= '0' && anchor[0] <= '9' {
+		anchor = "_" + anchor
+	}
+	anchor = strings.ToLower(anchor)
+
+	unique := anchor
+	for i := 1; g.used[unique]; i++ {
+		unique = anchor + "-" + strconv.Itoa(i)
+	}
+	g.used[unique] = true
+	return unique
+}
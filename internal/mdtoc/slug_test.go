@@ -0,0 +1,258 @@
+package mdtoc
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSlugger_Corpus(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]string // 规则名 -> 期望的锚点
+	}{
+		{
+			name:  "simple text",
+			input: "Hello World",
+			want:  map[string]string{SlugGitHub: "hello-world", SlugGitLab: "hello-world", SlugVitePress: "hello-world"},
+		},
+		{
+			name:  "unicode",
+			input: "This heading has Unicode in it. 한글",
+			want:  map[string]string{SlugGitHub: "this-heading-has-unicode-in-it-한글", SlugGitLab: "this-heading-has-unicode-in-it-한글"},
+		},
+		{
+			name:  "punctuation",
+			input: "This heading has 3.5 in it (and parentheses)",
+			want:  map[string]string{SlugGitHub: "this-heading-has-35-in-it-and-parentheses", SlugGitLab: "this-heading-has-35-in-it-and-parentheses", SlugVitePress: "this-heading-has-3-5-in-it-and-parentheses"},
+		},
+		{
+			name:  "spaces and hyphens",
+			input: "This heading has  multiple spaces and --- hyphens",
+			want:  map[string]string{SlugGitHub: "this-heading-has-multiple-spaces-and-hyphens", SlugGitLab: "this-heading-has-multiple-spaces-and-hyphens", SlugVitePress: "this-heading-has-multiple-spaces-and-hyphens", SlugHugo: "this-heading-has--multiple-spaces-and-----hyphens", SlugHugoBlackfriday: "this-heading-has-multiple-spaces-and-hyphens"},
+		},
+		{
+			name:  "trailing hyphen",
+			input: "Release -",
+			want:  map[string]string{SlugGitHub: "release", SlugGitLab: "release-", SlugVitePress: "release"},
+		},
+		{
+			name:  "leading punctuation",
+			input: "!Important",
+			want:  map[string]string{SlugGitHub: "important", SlugGitLab: "important", SlugVitePress: "important"},
+		},
+		{
+			name:  "digits only",
+			input: "2024",
+			want:  map[string]string{SlugGitHub: "2024", SlugGitLab: "anchor-2024", SlugVitePress: "_2024"},
+		},
+		{
+			name:  "combining marks",
+			input: "हिन्दी",
+			want:  map[string]string{SlugGitLab: "हिन्दी", SlugVitePress: "हिन्दी"},
+		},
+		{
+			name:  "code and emphasis",
+			input: "Using `fmt.Println` with **bold**",
+			want:  map[string]string{SlugGitHub: "using-fmtprintln-with-bold", SlugGitLab: "using-fmtprintln-with-bold", SlugVitePress: "using-fmt-println-with-bold"},
+		},
+		{
+			name:  "html tags",
+			input: "Install <small>v2</small>",
+			want:  map[string]string{SlugGitHub: "install-v2", SlugGitLab: "install-v2", SlugVitePress: "install-v2"},
+		},
+		{
+			name:  "underscores",
+			input: "snake_case name",
+			want:  map[string]string{SlugGitHub: "snake_case-name", SlugGitLab: "snake_case-name", SlugVitePress: "snake-case-name"},
+		},
+		{
+			name:  "accents",
+			input: "Café Résumé",
+			want:  map[string]string{SlugGitHub: "café-résumé", SlugVitePress: "cafe-resume", SlugHugo: "café-résumé", SlugHugoASCII: "cafe-resume", SlugHugoBlackfriday: "café-résumé"},
+		},
+		{
+			name:  "cjk punctuation",
+			input: "安装、配置，运行 (Linux)",
+			// 全角逗号经 NFKD 转为 ASCII 逗号后替换为连字符，顿号保持不变
+			want: map[string]string{SlugGitHub: "安装配置运行-linux", SlugVitePress: "安装、配置-运行-linux"},
+		},
+		{
+			name:  "leading digit",
+			input: "1. Getting Started",
+			want:  map[string]string{SlugGitHub: "1-getting-started", SlugVitePress: "_1-getting-started"},
+		},
+		{
+			name:  "non-ascii only",
+			input: "中文标题",
+			want:  map[string]string{SlugGitHub: "中文标题", SlugHugo: "中文标题", SlugHugoASCII: "heading"},
+		},
+		{
+			name:  "fullwidth and hangul",
+			input: "ＡＰＩ 한글",
+			// NFKD 将全角字母转为半角，将韩文音节分解为字母 (与浏览器中 VitePress 生成的 id 一致)
+			want: map[string]string{SlugVitePress: "api-\u1112\u1161\u11ab\u1100\u1173\u11af"},
+		},
+	}
+
+	for _, tt := range tests {
+		for slug, expected := range tt.want {
+			t.Run(slug+"/"+tt.name, func(t *testing.T) {
+				s, err := NewSlugger(slug)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := s.Generate(tt.input); got != expected {
+					t.Errorf("Generate(%q) = %q, want %q", tt.input, got, expected)
+				}
+			})
+		}
+	}
+}
+
+func TestSlugger_Duplicates(t *testing.T) {
+	inputs := []string{"Title", "Title", "Title", "Other", "2024", "2024"}
+	tests := map[string][]string{
+		SlugGitHub:    {"title", "title-1", "title-2", "other", "2024", "2024-1"},
+		SlugGitLab:    {"title", "title-1", "title-2", "other", "anchor-2024", "anchor-2024-1"},
+		SlugVitePress: {"title", "title-1", "title-2", "other", "_2024", "_2024-1"},
+		SlugHugo:      {"title", "title-1", "title-2", "other", "2024", "2024-1"},
+	}
+
+	for slug, expected := range tests {
+		t.Run(slug, func(t *testing.T) {
+			s, err := NewSlugger(slug)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, in := range inputs {
+				got = append(got, s.Generate(in))
+			}
+			if !slices.Equal(got, expected) {
+				t.Errorf("Generate() = %q, want %q", got, expected)
+			}
+		})
+	}
+}
+
+func TestVitePressSlugger_Unique(t *testing.T) {
+	// markdown-it-anchor 检查所有已使用的锚点，生成的后缀不会与显式写出的标题冲突
+	s := NewVitePressSlugger()
+	var got []string
+	for _, in := range []string{"a", "a-1", "a", "a"} {
+		got = append(got, s.Generate(in))
+	}
+	if expected := []string{"a", "a-1", "a-2", "a-3"}; !slices.Equal(got, expected) {
+		t.Errorf("Generate() = %q, want %q", got, expected)
+	}
+}
+
+func TestNewSlugger(t *testing.T) {
+	if s, err := NewSlugger(""); err != nil {
+		t.Fatal(err)
+	} else if _, ok := s.(*AnchorGenerator); !ok {
+		t.Errorf("NewSlugger(\"\") = %T, want *AnchorGenerator", s)
+	}
+
+	if _, err := NewSlugger("bitbucket"); err == nil {
+		t.Error("NewSlugger(\"bitbucket\") should return error")
+	}
+}
+
+func TestParser_Slug(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Slug = SlugGitLab
+	headers, err := NewParser(opts).Parse([]byte("# 2024\n\n## Release -\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var anchors []string
+	for _, h := range headers {
+		anchors = append(anchors, h.AnchorLink)
+	}
+	if expected := []string{"anchor-2024", "release-"}; !slices.Equal(anchors, expected) {
+		t.Errorf("anchors = %q, want %q", anchors, expected)
+	}
+}
+
+func TestParser_HeadingIDSlug(t *testing.T) {
+	// Hugo 规则的 id 由 goldmark 按标题的 Markdown 原文生成，链接地址等标记同样参与转换
+	content := "# Hello World\n## See [Docs](https://x.io/a)\n## Release -\n## Hello World\nSetext\n------\n"
+	tests := map[string][]string{
+		SlugHugo:            {"hello-world", "see-docshttpsxioa", "release--", "hello-world-1", "setext"},
+		SlugHugoBlackfriday: {"hello-world", "see-docs-https-x-io-a", "release", "hello-world-1", "setext"},
+	}
+
+	for slug, expected := range tests {
+		t.Run(slug, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Slug = slug
+			headers, err := NewParser(opts).Parse([]byte(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var anchors []string
+			for _, h := range headers {
+				anchors = append(anchors, h.AnchorLink)
+			}
+			if !slices.Equal(anchors, expected) {
+				t.Errorf("anchors = %q, want %q", anchors, expected)
+			}
+		})
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "更新 testdata 中的 golden 文件")
+
+// TestSlugger_Golden 使用同一组标题对比各规则的输出
+// 标题按顺序输入同一个生成器，重复标题的后缀同样参与比较；修改规则后使用 go test -update 重新生成
+func TestSlugger_Golden(t *testing.T) {
+	dir := filepath.Join("testdata", "slug")
+	input, err := os.ReadFile(filepath.Join(dir, "headings.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headings := strings.Split(strings.TrimSuffix(string(input), "\n"), "\n")
+
+	for _, name := range SlugNames() {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewSlugger(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, h := range headings {
+				got = append(got, s.Generate(h))
+			}
+
+			path := filepath.Join(dir, name+".golden")
+			if *updateGolden {
+				if err := os.WriteFile(path, []byte(strings.Join(got, "\n")+"\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			if len(expected) != len(headings) {
+				t.Fatalf("%s has %d lines, want %d", path, len(expected), len(headings))
+			}
+			for i, h := range headings {
+				if got[i] != expected[i] {
+					t.Errorf("Generate(%q) = %q, want %q", h, got[i], expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package mdtoc
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Template 用户自定义的 TOC 模板 (text/template)
+//
+// 模板文件可定义以下命名模板：
+//   - entry: 渲染单个条目，数据为 *TemplateEntry；未定义时使用整个文件作为 entry 模板
+//   - header / footer: 渲染在所有条目之前 / 之后，数据为 *TemplateData (可选)
+//
+// 各部分输出去除末尾空白后按行拼接，输出为空的条目会被跳过
+type Template struct {
+	tmpl *template.Template
+}
+
+// TemplateEntry 模板中单个 TOC 条目的数据
+type TemplateEntry struct {
+	Level      int              // 标题层级 (1-6)
+	Depth      int              // 在 TOC 树中的嵌套深度 (从 0 开始)
+	Text       string           // 标题文本
+	AnchorLink string           // 锚点
+	Line       int              // 标题所在行
+	EndLine    int              // 内容结束行
+	Count      int              // 行数 (EndLine - Line + 1)
+	FilePath   string           // 当前文件路径
+	Index      int              // 在整个 TOC 中的序号 (从 1 开始)
+	Number     int              // 在同级条目中的序号 (从 1 开始)
+	Numbering  string           // 多级编号，如 2.1.3
+	Parent     *TemplateEntry   // 父条目，顶层条目为 nil
+	Children   []*TemplateEntry // 子条目
+}
+
+// TemplateData header/footer 模板的数据
+type TemplateData struct {
+	FilePath string           // 当前文件路径
+	Entries  []*TemplateEntry // 按文档顺序排列的所有条目
+	Roots    []*TemplateEntry // 顶层条目
+}
+
+// templateFuncs 模板中可用的辅助函数
+var templateFuncs = template.FuncMap{
+	"repeat": func(s string, n int) string { return strings.Repeat(s, max(n, 0)) },
+	"lower":  strings.ToLower,
+	"upper":  strings.ToUpper,
+}
+
+// LoadTemplate 从文件加载 TOC 模板
+func LoadTemplate(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTemplate(filepath.Base(path), string(data))
+}
+
+// ParseTemplate 解析 TOC 模板
+// 解析后使用示例数据试运行一次，字段名或函数调用错误在加载时即报告
+func ParseTemplate(name, text string) (*Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	t := &Template{tmpl: tmpl}
+
+	// 示例数据覆盖顶层/子条目、有无子条目等情况
+	sample := []*Header{
+		{Level: 2, Text: "Example", AnchorLink: "example", Line: 1, EndLine: 4},
+		{Level: 3, Text: "Child", AnchorLink: "child", Line: 3, EndLine: 4},
+	}
+	if _, err := t.Render(sample, "example.md"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render 使用模板渲染标题列表
+func (t *Template) Render(headers []*Header, filePath string) (string, error) {
+	data := newTemplateData(headers, filePath)
+
+	var parts []string
+	render := func(name string, v any) error {
+		var sb strings.Builder
+		if err := t.tmpl.ExecuteTemplate(&sb, name, v); err != nil {
+			return err
+		}
+		if out := strings.TrimRight(sb.String(), " \t\n"); out != "" {
+			parts = append(parts, out)
+		}
+		return nil
+	}
+
+	if t.tmpl.Lookup("header") != nil {
+		if err := render("header", data); err != nil {
+			return "", err
+		}
+	}
+
+	entry := t.tmpl.Name()
+	if t.tmpl.Lookup("entry") != nil {
+		entry = "entry"
+	}
+	for _, e := range data.Entries {
+		if err := render(entry, e); err != nil {
+			return "", err
+		}
+	}
+
+	if t.tmpl.Lookup("footer") != nil {
+		if err := render("footer", data); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.Join(parts, "\n"), nil
+}
+
+// newTemplateData 将标题列表转换为模板数据 (按层级建立父子关系并计算编号)
+func newTemplateData(headers []*Header, filePath string) *TemplateData {
+	data := &TemplateData{FilePath: filePath}
+	var stack []*TemplateEntry
+
+	for i, h := range headers {
+		e := &TemplateEntry{
+			Level:      h.Level,
+			Text:       h.Text,
+			AnchorLink: h.AnchorLink,
+			Line:       h.Line,
+			EndLine:    h.EndLine,
+			Count:      h.EndLine - h.Line + 1,
+			FilePath:   filePath,
+			Index:      i + 1,
+		}
+
+		// 弹出层级不低于当前标题的条目，栈顶即为父条目
+		for len(stack) > 0 && stack[len(stack)-1].Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			data.Roots = append(data.Roots, e)
+			e.Number = len(data.Roots)
+			e.Numbering = strconv.Itoa(e.Number)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, e)
+			e.Parent = parent
+			e.Depth = parent.Depth + 1
+			e.Number = len(parent.Children)
+			e.Numbering = parent.Numbering + "." + strconv.Itoa(e.Number)
+		}
+
+		stack = append(stack, e)
+		data.Entries = append(data.Entries, e)
+	}
+
+	return data
+}
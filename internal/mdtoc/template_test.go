@@ -0,0 +1,138 @@
+package mdtoc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplate_Render(t *testing.T) {
+	headers := []*Header{
+		{Level: 2, Text: "Install", AnchorLink: "install", Line: 3, EndLine: 10},
+		{Level: 3, Text: "Linux", AnchorLink: "linux", Line: 5, EndLine: 7},
+		{Level: 3, Text: "macOS", AnchorLink: "macos", Line: 8, EndLine: 10},
+		{Level: 2, Text: "Usage", AnchorLink: "usage", Line: 11, EndLine: 12},
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name: "entry only",
+			text: `{{repeat "  " .Depth}}* {{.Text}} ({{.Line}}-{{.EndLine}})`,
+			expected: `* Install (3-10)
+  * Linux (5-7)
+  * macOS (8-10)
+* Usage (11-12)`,
+		},
+		{
+			name: "header footer and numbering",
+			text: `{{define "header"}}<!-- {{len .Entries}} entries in {{.FilePath}} -->{{end}}
+{{define "entry"}}{{.Numbering}} [{{.Text}}](#{{.AnchorLink}}){{if .Parent}} < {{.Parent.Text}}{{end}}{{end}}
+{{define "footer"}}{{range .Roots}}{{.Text}}:{{len .Children}} {{end}}{{end}}`,
+			expected: `<!-- 4 entries in a.md -->
+1 [Install](#install)
+1.1 [Linux](#linux) < Install
+1.2 [macOS](#macos) < Install
+2 [Usage](#usage)
+Install:2 Usage:0`,
+		},
+		{
+			name: "empty entries skipped",
+			text: `{{if eq .Level 2}}{{.Index}}. {{upper .Text}}{{end}}`,
+			expected: `1. INSTALL
+4. USAGE`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate("test.tmpl", tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tmpl.Render(headers, "a.md")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.expected {
+				t.Errorf("Render() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"syntax error", `{{.Text`},
+		{"unknown field", `{{.Title}}`},
+		{"unguarded parent", `{{.Parent.Text}}`},
+		{"unknown function", `{{missing .Text}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTemplate("bad.tmpl", tt.text); err == nil {
+				t.Error("ParseTemplate() should return error")
+			}
+		})
+	}
+}
+
+func TestTOC_Template(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "toc.tmpl")
+	if err := os.WriteFile(path, []byte(`{{repeat "  " .Depth}}+ [{{.Text}}](#{{.AnchorLink}}) L{{.Line}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	toc := New(Options{MinLevel: 2, MaxLevel: 3, ShowAnchor: true, SectionTOC: true, Template: tmpl})
+	content := "# Title\n\n## A\n\n### B\n"
+
+	got, err := toc.UpdateContent([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "+ [A](#a) L10\n  + [B](#b) L12") {
+		t.Errorf("UpdateContent() should render entries with template, got:\n%s", got)
+	}
+
+	// 模板渲染的 TOC 同样保持稳定
+	again, err := toc.UpdateContent(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(got) {
+		t.Errorf("UpdateContent() not idempotent:\n%s\n---\n%s", got, again)
+	}
+}
+
+func TestTOC_Template_RenderError(t *testing.T) {
+	// 示例数据只覆盖 2-3 级标题，H4 的祖父节点才会在实际执行时暴露为 nil
+	tmpl, err := ParseTemplate("deep.tmpl", `{{if eq .Level 4}}{{.Parent.Parent.Parent.Text}}{{else}}{{.Text}}{{end}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+
+	toc := New(Options{MinLevel: 2, MaxLevel: 4, Template: tmpl})
+	content := "# Title\n\n<!--TOC-->\n<!--TOC-->\n\n## A\n\n### B\n\n#### C\n"
+
+	if _, err := toc.UpdateContent([]byte(content)); err == nil {
+		t.Error("UpdateContent() should return template render error")
+	}
+	if _, err := toc.Preview([]byte(content)); err == nil {
+		t.Error("Preview() should return template render error")
+	}
+	if _, err := toc.IsUpToDate([]byte(content)); err == nil {
+		t.Error("IsUpToDate() should return template render error")
+	}
+}
@@ -0,0 +1,96 @@
+package mdtoc
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// TreeOptions 终端树形视图选项
+type TreeOptions struct {
+	Color      bool   // 使用 ANSI 颜色区分标题层级
+	LineNumber bool   // 在右侧对齐显示行号范围
+	LineStyle  string // 行号范围格式 (LineStyle* 常量)，为空时使用 :start+count
+}
+
+// ANSI 颜色
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+)
+
+// treeLevelColors 各层级标题的颜色 (H1-H6)
+var treeLevelColors = [...]string{
+	"\x1b[1;35m", // H1 粗体品红
+	"\x1b[1;36m", // H2 粗体青色
+	"\x1b[32m",   // H3 绿色
+	"\x1b[33m",   // H4 黄色
+	"\x1b[34m",   // H5 蓝色
+	"\x1b[37m",   // H6 白色
+}
+
+// treeRow 树形视图中的一行
+type treeRow struct {
+	prefix string // 树枝前缀 (├── 等)
+	node   *OutlineNode
+	lines  string // 行号范围
+}
+
+// width 返回树枝前缀与标题的显示宽度
+// 树枝字符在东亚环境 (如 LANG=zh_CN.UTF-8) 下被 runewidth 视为宽字符，但终端按单宽显示，
+// 因此前缀按字符数计算，只有标题文本按显示宽度计算
+func (r treeRow) width() int {
+	return utf8.RuneCountInString(r.prefix) + runewidth.StringWidth(r.node.Text)
+}
+
+// RenderTree 将文件大纲渲染为终端树形视图
+// 行号范围按显示宽度右对齐，中日韩等宽字符的标题也能对齐
+func RenderTree(file *FileOutline, opts TreeOptions) string {
+	var rows []treeRow
+	var walk func(nodes []*OutlineNode, indent string)
+	walk = func(nodes []*OutlineNode, indent string) {
+		for i, n := range nodes {
+			branch, next := "├── ", "│   "
+			if i == len(nodes)-1 {
+				branch, next = "└── ", "    "
+			}
+			row := treeRow{prefix: indent + branch, node: n}
+			if opts.LineNumber && n.Line > 0 {
+				row.lines = formatLineRange(opts.LineStyle, "", n.Line, n.EndLine)
+			}
+			rows = append(rows, row)
+			walk(n.Children, indent+next)
+		}
+	}
+	walk(file.Headers, "")
+
+	// 计算标签列与行号列的宽度
+	labelWidth, linesWidth := 0, 0
+	for _, r := range rows {
+		labelWidth = max(labelWidth, r.width())
+		linesWidth = max(linesWidth, len(r.lines))
+	}
+
+	paint := func(s, color string) string {
+		if !opts.Color || s == "" {
+			return s
+		}
+		return color + s + ansiReset
+	}
+
+	var sb strings.Builder
+	sb.WriteString(paint(file.Path, ansiBold))
+	for _, r := range rows {
+		sb.WriteString("\n")
+		sb.WriteString(paint(r.prefix, ansiDim))
+		sb.WriteString(paint(r.node.Text, treeLevelColors[min(max(r.node.Level, 1), 6)-1]))
+		if r.lines != "" {
+			// 标签列补齐到相同宽度后留两个空格，行号范围在其后右对齐
+			sb.WriteString(strings.Repeat(" ", labelWidth-r.width()+2+linesWidth-len(r.lines)))
+			sb.WriteString(paint(r.lines, ansiDim))
+		}
+	}
+	return sb.String()
+}
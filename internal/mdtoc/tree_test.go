@@ -0,0 +1,83 @@
+package mdtoc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func TestRenderTree(t *testing.T) {
+	file := &FileOutline{
+		Path: "guide.md",
+		Headers: BuildTree([]*Header{
+			{Level: 1, Text: "指南", Line: 1, EndLine: 120},
+			{Level: 2, Text: "安装", Line: 3, EndLine: 9},
+			{Level: 3, Text: "Linux", Line: 5, EndLine: 9},
+			{Level: 2, Text: "Usage", Line: 10, EndLine: 120},
+		}),
+	}
+
+	t.Run("plain with line numbers", func(t *testing.T) {
+		got := RenderTree(file, TreeOptions{LineNumber: true})
+		// 中文标题按显示宽度 2 计算，行号范围右对齐
+		expected := `guide.md
+└── 指南            :1+120
+    ├── 安装          :3+7
+    │   └── Linux     :5+5
+    └── Usage      :10+111`
+		if got != expected {
+			t.Errorf("RenderTree() =\n%s\nwant\n%s", got, expected)
+		}
+	})
+
+	t.Run("east asian locale", func(t *testing.T) {
+		// LANG=zh_CN.UTF-8 等环境下 runewidth 将树枝字符视为宽字符，前缀宽度不应受影响
+		defer func(v bool) { runewidth.DefaultCondition.EastAsianWidth = v }(runewidth.DefaultCondition.EastAsianWidth)
+		runewidth.DefaultCondition.EastAsianWidth = true
+
+		got := RenderTree(file, TreeOptions{LineNumber: true})
+		expected := `guide.md
+└── 指南            :1+120
+    ├── 安装          :3+7
+    │   └── Linux     :5+5
+    └── Usage      :10+111`
+		if got != expected {
+			t.Errorf("RenderTree() =\n%s\nwant\n%s", got, expected)
+		}
+	})
+
+	t.Run("line style", func(t *testing.T) {
+		got := RenderTree(file, TreeOptions{LineNumber: true, LineStyle: LineStyleGitHub})
+		expected := `guide.md
+└── 指南            L1-L120
+    ├── 安装          L3-L9
+    │   └── Linux     L5-L9
+    └── Usage      L10-L120`
+		if got != expected {
+			t.Errorf("RenderTree() =\n%s\nwant\n%s", got, expected)
+		}
+	})
+
+	t.Run("without line numbers", func(t *testing.T) {
+		got := RenderTree(file, TreeOptions{})
+		expected := `guide.md
+└── 指南
+    ├── 安装
+    │   └── Linux
+    └── Usage`
+		if got != expected {
+			t.Errorf("RenderTree() =\n%s\nwant\n%s", got, expected)
+		}
+	})
+
+	t.Run("color", func(t *testing.T) {
+		got := RenderTree(file, TreeOptions{Color: true})
+		if !strings.Contains(got, treeLevelColors[0]+"指南"+ansiReset) {
+			t.Errorf("RenderTree() should color H1, got %q", got)
+		}
+		if !strings.Contains(got, treeLevelColors[2]+"Linux"+ansiReset) {
+			t.Errorf("RenderTree() should color H3, got %q", got)
+		}
+	})
+}
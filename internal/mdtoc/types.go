@@ -4,24 +4,65 @@ package mdtoc
 // Header 表示一个 Markdown 标题
 type Header struct {
 	Level      int    // 标题层级 (1-6)
-	Text       string // 标题文本 (原始文本，去除 # 和前后空格)
-	AnchorLink string // 锚点链接 (GitHub 风格)
+	Text       string // 标题文本 (原始文本，去除 #、属性块 {#id} 和前后空格)
+	AnchorLink string // 锚点链接 (显式指定的 {#id} 优先，否则按 Options.Slug 规则生成)
 	Line       int    // 标题所在行 (1-based)
 	EndLine    int    // 内容结束行 (1-based)，下一个标题前一行或文件末尾
 }
 
 // Options 配置 TOC 生成选项
 type Options struct {
-	MinLevel   int    // 最小标题层级 (默认 1)
-	MaxLevel   int    // 最大标题层级 (默认 3)
-	Ordered    bool   // 使用有序列表
-	LineNumber bool   // 显示行号范围 (:start:end)
-	ShowPath   bool   // 显示文件路径 (path:start:end)
-	FilePath   string // 当前处理的文件路径
-	SectionTOC bool   // 章节模式：每个 H1 后生成独立的子目录
-	ShowAnchor bool   // 显示锚点链接 [标题](#anchor)，预览默认 false，写入强制 true
+	MinLevel      int    // 最小标题层级 (默认 1)
+	MaxLevel      int    // 最大标题层级 (默认 3)
+	Ordered       bool   // 使用有序列表
+	LineNumber    bool   // 显示行号范围 (格式由 LineStyle 决定)
+	LineStyle     string // 行号范围格式 (LineStylePlus 等)，空值为 :start+count
+	LineExclusive bool   // 父标题的行号范围结束于第一个子标题之前，不包含子标题内容
+	ShowPath      bool   // 显示文件路径 (path:start+count)
+	FilePath      string // 当前处理的文件路径
+	SectionTOC    bool   // 章节模式：每个 H1 后生成独立的子目录
+	ShowAnchor    bool   // 显示锚点链接 [标题](#anchor)，预览默认 false，写入强制 true
+	Format        string // TOC 渲染格式 (FormatMarkdown/FormatHTML/FormatMermaid)，空值为 Markdown 列表
+	HTMLClass     string // HTML 格式下列表项的 CSS 类名前缀，按层级追加数字 (如 toc-h2)，空值不添加
+	HTMLInput     bool   // 输入为 HTML 文档：按 <h1>-<h6> 标签解析标题，只更新已有标记处的 TOC
+
+	List      ListStyle  // 列表符号、缩进和编号格式
+	Collapse  string     // 折叠样式 (CollapseDetails/CollapseGroups)，空值或 CollapseNone 不折叠
+	Summary   string     // 折叠时 <summary> 的文本，空值为 DefaultSummary
+	Slug      string     // 锚点生成规则 (SlugGitHub 等)，空值为 GitHub 规则
+	Template  *Template  // 自定义条目模板，设置后优先于 Format
+	Permalink *Permalink // 行号范围链接到源码托管平台，需同时启用 LineNumber
 }
 
+// TOC 渲染格式
+const (
+	FormatMarkdown = "markdown" // Markdown 列表 (默认)
+	FormatHTML     = "html"     // HTML <nav> 嵌套列表
+	FormatMermaid  = "mermaid"  // Mermaid mindmap 代码块
+)
+
+// TOC 折叠样式
+const (
+	CollapseNone    = "none"    // 不折叠 (用于覆盖配置文件中的设置)
+	CollapseDetails = "details" // TOC 包裹在 <details> 中
+	CollapseGroups  = "groups"  // 在 details 的基础上，每个带子条目的顶层条目再折叠为一组
+)
+
+// DefaultSummary 折叠 TOC 的默认 <summary> 文本
+const DefaultSummary = "Contents"
+
+// 行号范围格式 (以第 10-20 行为例，括号内为指定文件路径时的形式)
+const (
+	LineStylePlus   = "plus"   // :10+11 (path:10+11)，起始行 + 行数 (默认)
+	LineStyleColon  = "colon"  // 10:20 (path:10:20)
+	LineStyleGitHub = "github" // L10-L20 (path#L10-L20)
+	LineStyleDash   = "dash"   // 10-20 (path:10-20)
+	LineStyleStart  = "start"  // :10 (path:10)，只有起始行，适用于编辑器跳转列表
+)
+
+// LineStyles 支持的行号范围格式
+var LineStyles = []string{LineStylePlus, LineStyleColon, LineStyleGitHub, LineStyleDash, LineStyleStart}
+
 // Section 表示一个章节 (H1 及其子标题)
 type Section struct {
 	Title      *Header   // H1 标题
@@ -41,9 +82,10 @@ func DefaultOptions() Options {
 
 // TOCMarker 表示 TOC 标记位置
 type TOCMarker struct {
-	StartLine int // 第一个标记所在行号 (0-based)
-	EndLine   int // 第二个标记所在行号 (0-based), -1 表示只有一个标记
+	StartLine int    // 第一个标记所在行号 (0-based)
+	EndLine   int    // 第二个标记所在行号 (0-based), -1 表示只有一个标记
 	Found     bool
+	Options   string // 第一个标记中的内联选项原文，例如 "max-level=2 ordered"
 }
 
 // DefaultMarker 是默认的 TOC 标记字符串
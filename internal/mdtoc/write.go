@@ -0,0 +1,53 @@
+package mdtoc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile 原子地写入文件内容
+// 内容与 old 完全相同时跳过写入并返回 false
+// 写入目标为符号链接指向的实际文件，先写入同目录下的临时文件再重命名替换，
+// 中途失败不会留下截断的文件；新文件保留原文件的权限位
+func WriteFile(filename string, old, content []byte) (bool, error) {
+	if bytes.Equal(old, content) {
+		return false, nil
+	}
+
+	// 跟随符号链接，替换链接指向的文件而不是链接本身
+	target, err := filepath.EvalSymlinks(filename)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return false, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return false, err
+	}
+	// 重命名成功后临时文件已不存在，Remove 的错误可忽略
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return false, err
+	}
+	return true, nil
+}
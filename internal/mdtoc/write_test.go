@@ -0,0 +1,103 @@
+package mdtoc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	t.Run("unchanged content skipped", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "doc.md")
+		if err := os.WriteFile(path, []byte("# Title\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		before, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		written, err := WriteFile(path, []byte("# Title\n"), []byte("# Title\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written {
+			t.Error("WriteFile() = true, want false for identical content")
+		}
+
+		// 写入会通过重命名替换文件，未写入时仍是同一个文件
+		after, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !os.SameFile(before, after) {
+			t.Error("file was replaced although content is unchanged")
+		}
+	})
+
+	t.Run("mode preserved", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "doc.md")
+		if err := os.WriteFile(path, []byte("old"), 0640); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, 0750); err != nil {
+			t.Fatal(err)
+		}
+
+		written, err := WriteFile(path, []byte("old"), []byte("new"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !written {
+			t.Error("WriteFile() = false, want true")
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0750 {
+			t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0750))
+		}
+		if got, _ := os.ReadFile(path); string(got) != "new" {
+			t.Errorf("content = %q, want %q", got, "new")
+		}
+
+		// 不应残留临时文件
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("dir contains %d entries, want 1", len(entries))
+		}
+	})
+
+	t.Run("symlink followed", func(t *testing.T) {
+		dir := t.TempDir()
+		target := filepath.Join(dir, "target.md")
+		link := filepath.Join(dir, "link.md")
+		if err := os.WriteFile(target, []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink("target.md", link); err != nil {
+			t.Skipf("symlink not supported: %v", err)
+		}
+
+		if _, err := WriteFile(link, []byte("old"), []byte("new")); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := os.Lstat(link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			t.Error("link was replaced by a regular file")
+		}
+		if got, _ := os.ReadFile(target); string(got) != "new" {
+			t.Errorf("target content = %q, want %q", got, "new")
+		}
+	})
+}